@@ -0,0 +1,89 @@
+package nest
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// aliasDateLayout is the format of the alias sunset dates.
+const aliasDateLayout = "2006-01-02"
+
+// aliasFlagName converts a former key to a flag name (eg. old.key.path to old-key-path).
+func aliasFlagName(alias string, delimiter string) string {
+	return strings.ToLower(strings.Replace(alias, delimiter, "-", -1))
+}
+
+// aliasEnvName converts a former key to an environment variable name (eg. old.key.path to OLD_KEY_PATH).
+func aliasEnvName(alias string, delimiter string) string {
+	return strings.ToUpper(strings.Replace(alias, delimiter, "_", -1))
+}
+
+// defineAliasFlags registers hidden flags for the former keys of a field sharing the value of the field's flag.
+func (c *Configurator) defineAliasFlags(flags *pflag.FlagSet, flag *pflag.Flag, def fieldDefinition) []*pflag.Flag {
+	aliasFlags := make([]*pflag.Flag, 0, len(def.keyAliases))
+
+	for _, alias := range def.keyAliases {
+		name := c.mergeWithFlagPrefix(aliasFlagName(alias, c.getKeyDelimiter()))
+
+		// The flag set is kept between loads
+		if aliasFlag := flags.Lookup(name); aliasFlag == nil {
+			flags.AddFlag(&pflag.Flag{
+				Name:        name,
+				Usage:       flag.Usage,
+				Value:       flag.Value,
+				DefValue:    flag.DefValue,
+				NoOptDefVal: flag.NoOptDefVal,
+				Hidden:      true,
+			})
+		} else {
+			aliasFlag.Changed = false
+		}
+
+		aliasFlags = append(aliasFlags, flags.Lookup(name))
+	}
+
+	return aliasFlags
+}
+
+// lookupAliasEnv returns the first environment variable set for the former keys of a field.
+func (c *Configurator) lookupAliasEnv(def fieldDefinition) (string, bool) {
+	for _, alias := range def.keyAliases {
+		if actualName, ok := c.lookupEnv(c.mergeWithEnvPrefix(aliasEnvName(alias, c.getKeyDelimiter()))); ok {
+			return actualName, true
+		}
+	}
+
+	return "", false
+}
+
+// getKeyDelimiter returns the delimiter joining the keys of nested structs.
+func (c *Configurator) getKeyDelimiter() string {
+	if c.keyDelimiter == "" {
+		return "."
+	}
+
+	return c.keyDelimiter
+}
+
+// useAlias reports the usage of a former key of a field (eg. kind "flag", name "--old-flag").
+// It's a warning until the end of the sunset date of the aliases and an error afterwards.
+func (c *Configurator) useAlias(report *Report, def fieldDefinition, kind string, name string, current string) error {
+	if def.aliasSunset.IsZero() {
+		c.warn(report, "%s %s is deprecated, use %s instead", kind, name, current)
+
+		return nil
+	}
+
+	sunset := def.aliasSunset.Format(aliasDateLayout)
+
+	if !time.Now().Before(def.aliasSunset.AddDate(0, 0, 1)) {
+		return errors.New(c.translate(MsgAliasExpired, kind, name, sunset, current))
+	}
+
+	c.warn(report, "%s %s is deprecated and will stop working after %s, use %s instead", kind, name, sunset, current)
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package nest_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurator_Load_Alias(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_DB_USER", "admin")
+
+	type config struct {
+		Database struct {
+			Host     string `alias:"db.host"`
+			Port     int    `flag:"" alias:"db.port"`
+			User     string `env:"" alias:"db.user"`
+			Password string `alias:"db.pass, db.password"`
+			Name     string `env:"" flag:"" alias:"db.name"`
+		}
+	}
+
+	c := config{}
+
+	var buf bytes.Buffer
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app", "--db-port", "3306", "--database-name", "app"})
+	configurator.SetEnvPrefix("app")
+	configurator.SetErrorOutput(&buf)
+	configurator.AddSource(mapSource{
+		"db.host":        "db.example.com",
+		"db.password":    "s3cr3t",
+		"db.name":        "legacy",
+		"Database.Name":  "source",
+		"Database.Other": "other",
+	})
+
+	report, err := configurator.LoadWithReport(&c)
+	require.NoError(t, err)
+
+	assert.Equal(t, "db.example.com", c.Database.Host)
+	assert.Equal(t, 3306, c.Database.Port)
+	assert.Equal(t, "admin", c.Database.User)
+	assert.Equal(t, "s3cr3t", c.Database.Password)
+	assert.Equal(t, "app", c.Database.Name)
+
+	assert.Equal(t, []string{
+		"configuration key db.host is deprecated, use Database.Host instead",
+		"configuration key db.password is deprecated, use Database.Password instead",
+		"environment variable APP_DB_USER is deprecated, use APP_DATABASE_USER instead",
+		"flag --db-port is deprecated, use --database-port instead",
+	}, report.Warnings)
+	assert.Equal(t, nest.OriginEnv, report.Field("Database.User").Origin)
+	assert.Equal(t, "APP_DB_USER", report.Field("Database.User").Name)
+
+	os.Clearenv()
+}
+
+func TestConfigurator_Load_AliasBoolFlag(t *testing.T) {
+	type config struct {
+		Debug bool `flag:"" alias:"verbose"`
+	}
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app", "--verbose"})
+	configurator.SetErrorOutput(&bytes.Buffer{})
+
+	err := configurator.Load(&c)
+	require.NoError(t, err)
+	assert.True(t, c.Debug)
+}
+
+func TestSchema_Aliases(t *testing.T) {
+	type config struct {
+		Host string `alias:"db.host"`
+	}
+
+	schema, err := nest.NewConfigurator().Parse(&config{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db.host"}, schema.Field("Host").Aliases)
+}
+
+func TestConfigurator_Load_AliasDeprecatedAfter(t *testing.T) {
+	type config struct {
+		Host string `alias:"db.host" alias_deprecated_after:"2999-12-31"`
+	}
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.SetErrorOutput(&bytes.Buffer{})
+	configurator.AddSource(mapSource{"db.host": "db.example.com"})
+
+	report, err := configurator.LoadWithReport(&c)
+	require.NoError(t, err)
+	assert.Equal(t, "db.example.com", c.Host)
+	assert.Equal(t, []string{"configuration key db.host is deprecated and will stop working after 2999-12-31, use Host instead"}, report.Warnings)
+}
+
+func TestConfigurator_Load_AliasExpired(t *testing.T) {
+	type config struct {
+		Debug bool `flag:"" alias:"verbose" alias_deprecated_after:"2000-01-01"`
+	}
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app", "--verbose"})
+
+	err := configurator.Load(&c)
+	assert.EqualError(t, err, "flag --verbose is not supported after 2000-01-01, use --debug instead")
+}
+
+func TestConfigurator_Load_AliasDeprecatedAfterInvalid(t *testing.T) {
+	type config struct {
+		Host string `alias:"db.host" alias_deprecated_after:"tomorrow"`
+	}
+
+	err := nest.NewConfigurator().Load(&config{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid alias_deprecated_after for field Host")
+}
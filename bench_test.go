@@ -0,0 +1,90 @@
+package nest_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/goph/nest"
+)
+
+type benchSmallConfig struct {
+	Host string `env:"" flag:"" default:"localhost"`
+	Port int    `env:"" flag:"" default:"8080"`
+	Yes  bool   `env:"" flag:"" default:"true"`
+}
+
+type benchMediumConfig struct {
+	App struct {
+		Name    string        `env:"" flag:"" default:"app" usage:"Application name"`
+		Debug   bool          `env:"" flag:"" default:"false" usage:"Debug mode"`
+		Timeout time.Duration `env:"" flag:"" default:"10s" usage:"Request timeout"`
+	}
+
+	Database struct {
+		Host     string  `env:"" flag:"" default:"localhost"`
+		Port     int     `env:"" flag:"" default:"5432"`
+		User     string  `env:"" flag:"" default:"user"`
+		Password string  `env:"" flag:"" default:"password"`
+		Name     string  `env:"" flag:"" default:"db"`
+		Ratio    float64 `env:"" flag:"" default:"0.5"`
+	}
+
+	Redis struct {
+		Host string `env:"" flag:"" default:"localhost"`
+		Port int    `env:"" flag:"" default:"6379"`
+		DB   uint8  `env:"" flag:"" default:"0"`
+	}
+
+	Server struct {
+		Addr         string        `env:"" flag:"" default:":8080"`
+		ReadTimeout  time.Duration `env:"" flag:"" default:"5s"`
+		WriteTimeout time.Duration `env:"" flag:"" default:"5s"`
+		MaxConns     int64         `env:"" flag:"" default:"1000"`
+	}
+}
+
+// benchLargeConfig returns a pointer to a struct with the given number of fields.
+func benchLargeConfig(n int) func() interface{} {
+	fields := make([]reflect.StructField, n)
+	for i := 0; i < n; i++ {
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("Value%d", i),
+			Type: reflect.TypeOf(""),
+			Tag:  reflect.StructTag(`env:"" flag:"" default:"default"`),
+		}
+	}
+
+	typ := reflect.StructOf(fields)
+
+	return func() interface{} {
+		return reflect.New(typ).Interface()
+	}
+}
+
+func benchmarkLoad(b *testing.B, config func() interface{}) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		configurator := nest.NewConfigurator()
+		configurator.SetArgs([]string{"program"})
+
+		err := configurator.Load(config())
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkConfigurator_Load_Small(b *testing.B) {
+	benchmarkLoad(b, func() interface{} { return new(benchSmallConfig) })
+}
+
+func BenchmarkConfigurator_Load_Medium(b *testing.B) {
+	benchmarkLoad(b, func() interface{} { return new(benchMediumConfig) })
+}
+
+func BenchmarkConfigurator_Load_Large(b *testing.B) {
+	benchmarkLoad(b, benchLargeConfig(200))
+}
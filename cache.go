@@ -0,0 +1,197 @@
+package nest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrCacheIntegrity is returned when the checksum of a cache file does not match it's content.
+var ErrCacheIntegrity = errors.New("cache file integrity check failed")
+
+// cacheEntry is a single value fetched from a source.
+type cacheEntry struct {
+	Value     string    `json:"value"`
+	Found     bool      `json:"found"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// cacheFile is the on-disk representation of the cache.
+type cacheFile struct {
+	Checksum string          `json:"checksum"`
+	Entries  json.RawMessage `json:"entries"`
+}
+
+// CachedSource wraps a Source and stores the fetched values on disk.
+//
+// Values younger than the TTL are served from the cache without contacting the underlying source.
+// When the source cannot be reached the last fetched value is used (unless freshness is required),
+// so that services can start when the remote backend is briefly unavailable.
+//
+// Fetched values are kept in memory until FlushSource is called (the configurator does that at the end of every load),
+// so that the cache file is written once instead of for every key.
+type CachedSource struct {
+	source Source
+	path   string
+	ttl    time.Duration
+
+	requireFresh bool
+
+	entries map[string]cacheEntry
+	dirty   bool
+	now     func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewCachedSource returns a new CachedSource persisting values in the file located at path.
+func NewCachedSource(source Source, path string, ttl time.Duration) *CachedSource {
+	return &CachedSource{
+		source: source,
+		path:   path,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// SetRequireFresh disables falling back to stale cached values when the underlying source fails.
+func (s *CachedSource) SetRequireFresh(requireFresh bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requireFresh = requireFresh
+}
+
+// Lookup implements the Source interface.
+func (s *CachedSource) Lookup(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entries == nil {
+		entries, err := readCacheFile(s.path)
+		if err != nil && !os.IsNotExist(err) && err != ErrCacheIntegrity {
+			return "", false, err
+		}
+
+		// A missing or corrupted cache file is treated as an empty cache
+		if entries == nil {
+			entries = make(map[string]cacheEntry)
+		}
+
+		s.entries = entries
+	}
+
+	entry, cached := s.entries[key]
+	if cached && s.now().Sub(entry.FetchedAt) < s.ttl {
+		return entry.Value, entry.Found, nil
+	}
+
+	value, ok, err := s.source.Lookup(key)
+	if err != nil {
+		if cached && !s.requireFresh {
+			return entry.Value, entry.Found, nil
+		}
+
+		return "", false, err
+	}
+
+	s.entries[key] = cacheEntry{
+		Value:     value,
+		Found:     ok,
+		FetchedAt: s.now(),
+	}
+	s.dirty = true
+
+	return value, ok, nil
+}
+
+// FlushSource implements the SourceFlusher interface: it writes the values fetched since the last flush to the cache file.
+func (s *CachedSource) FlushSource() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+
+	if err := writeCacheFile(s.path, s.entries); err != nil {
+		return err
+	}
+
+	s.dirty = false
+
+	return nil
+}
+
+// readCacheFile reads the cache entries from a file and verifies their integrity.
+func readCacheFile(path string) (map[string]cacheEntry, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, ErrCacheIntegrity
+	}
+
+	if file.Checksum != checksum(file.Entries) {
+		return nil, ErrCacheIntegrity
+	}
+
+	var entries map[string]cacheEntry
+	if err := json.Unmarshal(file.Entries, &entries); err != nil {
+		return nil, ErrCacheIntegrity
+	}
+
+	return entries, nil
+}
+
+// writeCacheFile atomically writes the cache entries to a file.
+func writeCacheFile(path string, entries map[string]cacheEntry) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	data, err = json.Marshal(cacheFile{
+		Checksum: checksum(data),
+		Entries:  data,
+	})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// checksum calculates the SHA-256 checksum of a byte slice.
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
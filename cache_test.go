@@ -0,0 +1,199 @@
+package nest_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// toggleSource is a Source which can be switched off to simulate an unreachable backend.
+type toggleSource struct {
+	values  map[string]string
+	down    bool
+	lookups int
+}
+
+func (s *toggleSource) Lookup(key string) (string, bool, error) {
+	s.lookups++
+
+	if s.down {
+		return "", false, errors.New("source unavailable")
+	}
+
+	value, ok := s.values[key]
+
+	return value, ok, nil
+}
+
+func newCacheFile(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "nest")
+	require.NoError(t, err)
+
+	return filepath.Join(dir, "cache.json"), func() { os.RemoveAll(dir) }
+}
+
+func TestCachedSource_Fresh(t *testing.T) {
+	path, cleanup := newCacheFile(t)
+	defer cleanup()
+
+	source := &toggleSource{values: map[string]string{"key": "value"}}
+	cache := nest.NewCachedSource(source, path, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		value, ok, err := cache.Lookup("key")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "value", value)
+	}
+
+	assert.Equal(t, 1, source.lookups)
+}
+
+func TestCachedSource_Fallback(t *testing.T) {
+	path, cleanup := newCacheFile(t)
+	defer cleanup()
+
+	source := &toggleSource{values: map[string]string{"key": "value"}}
+
+	cache := nest.NewCachedSource(source, path, 0)
+
+	_, _, err := cache.Lookup("key")
+	require.NoError(t, err)
+	require.NoError(t, cache.FlushSource())
+
+	source.down = true
+
+	value, ok, err := nest.NewCachedSource(source, path, 0).Lookup("key")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "value", value)
+}
+
+func TestCachedSource_RequireFresh(t *testing.T) {
+	path, cleanup := newCacheFile(t)
+	defer cleanup()
+
+	source := &toggleSource{values: map[string]string{"key": "value"}}
+
+	cache := nest.NewCachedSource(source, path, 0)
+
+	_, _, err := cache.Lookup("key")
+	require.NoError(t, err)
+	require.NoError(t, cache.FlushSource())
+
+	source.down = true
+
+	cache = nest.NewCachedSource(source, path, 0)
+	cache.SetRequireFresh(true)
+
+	_, _, err = cache.Lookup("key")
+	require.Error(t, err)
+	assert.EqualError(t, err, "source unavailable")
+}
+
+func TestCachedSource_Integrity(t *testing.T) {
+	path, cleanup := newCacheFile(t)
+	defer cleanup()
+
+	source := &toggleSource{values: map[string]string{"key": "value"}}
+
+	cache := nest.NewCachedSource(source, path, 0)
+
+	_, _, err := cache.Lookup("key")
+	require.NoError(t, err)
+	require.NoError(t, cache.FlushSource())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	// Tamper with the cached value
+	data = []byte(strings.Replace(string(data), `"value":"value"`, `"value":"evil"`, 1))
+	require.NoError(t, ioutil.WriteFile(path, data, 0600))
+
+	source.down = true
+
+	_, _, err = nest.NewCachedSource(source, path, 0).Lookup("key")
+	require.Error(t, err)
+	assert.EqualError(t, err, "source unavailable")
+}
+
+func TestCachedSource_WriteFailure(t *testing.T) {
+	path, cleanup := newCacheFile(t)
+	defer cleanup()
+
+	source := &toggleSource{values: map[string]string{"key": "value"}}
+	cache := nest.NewCachedSource(source, filepath.Join(path, "missing", "cache.json"), 0)
+
+	value, ok, err := cache.Lookup("key")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "value", value)
+
+	assert.Error(t, cache.FlushSource())
+}
+
+func TestConfigurator_Load_CachedSource(t *testing.T) {
+	type config struct {
+		Key   string
+		Other string
+	}
+
+	path, cleanup := newCacheFile(t)
+	defer cleanup()
+
+	source := &toggleSource{values: map[string]string{"Key": "value", "Other": "other"}}
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.AddSource(nest.NewCachedSource(source, path, time.Hour))
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, config{"value", "other"}, actual)
+
+	source.down = true
+
+	actual = config{}
+
+	configurator = nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.AddSource(nest.NewCachedSource(source, path, 0))
+
+	err = configurator.Load(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, config{"value", "other"}, actual)
+}
+
+func TestConfigurator_Load_CachedSourceWriteFailure(t *testing.T) {
+	type config struct {
+		Key string
+	}
+
+	path, cleanup := newCacheFile(t)
+	defer cleanup()
+
+	source := &toggleSource{values: map[string]string{"Key": "value"}}
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.SetErrorOutput(ioutil.Discard)
+	configurator.AddSource(nest.NewCachedSource(source, filepath.Join(path, "missing", "cache.json"), 0))
+
+	report, err := configurator.LoadWithReport(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, "value", actual.Key)
+	require.Len(t, report.Warnings, 1)
+	assert.Contains(t, report.Warnings[0], "failed to flush source")
+}
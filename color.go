@@ -0,0 +1,70 @@
+package nest
+
+import (
+	"io"
+	"os"
+)
+
+// ColorMode controls whether the help output is colored.
+type ColorMode int
+
+const (
+	// ColorNever never colors the output (default).
+	ColorNever ColorMode = iota
+
+	// ColorAuto colors the output when it is written to a terminal and NO_COLOR is not set (to a non-empty value).
+	ColorAuto
+
+	// ColorAlways always colors the output.
+	ColorAlways
+)
+
+// ANSI escape sequences used in the help output.
+const (
+	colorReset    = "\x1b[0m"
+	colorName     = "\x1b[1;36m"
+	colorRequired = "\x1b[31m"
+	colorDefault  = "\x1b[2m"
+)
+
+// useColor decides whether output written to w should be colored.
+func useColor(mode ColorMode, w io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+
+	case ColorNever:
+		return false
+	}
+
+	// See https://no-color.org
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	return isTerminal(w)
+}
+
+// isTerminal checks whether a writer is a terminal (character device).
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorize wraps a string in an ANSI color sequence.
+func colorize(s string, color string, enabled bool) string {
+	if !enabled || s == "" {
+		return s
+	}
+
+	return color + s + colorReset
+}
@@ -0,0 +1,48 @@
+package nest
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseColor(t *testing.T) {
+	os.Clearenv()
+
+	var buf bytes.Buffer
+
+	assert.True(t, useColor(ColorAlways, &buf))
+	assert.False(t, useColor(ColorNever, &buf))
+	assert.False(t, useColor(ColorAuto, &buf))
+
+	os.Clearenv()
+}
+
+func TestUseColor_Default(t *testing.T) {
+	var mode ColorMode
+
+	assert.Equal(t, ColorNever, mode)
+}
+
+func TestUseColor_NoColor(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("NO_COLOR", "1")
+
+	assert.False(t, useColor(ColorAuto, os.Stdout))
+	assert.True(t, useColor(ColorAlways, os.Stdout))
+
+	// An empty value does not disable colors
+	os.Setenv("NO_COLOR", "")
+
+	assert.Equal(t, isTerminal(os.Stdout), useColor(ColorAuto, os.Stdout))
+
+	os.Clearenv()
+}
+
+func TestColorize(t *testing.T) {
+	assert.Equal(t, "\x1b[31mvalue\x1b[0m", colorize("value", colorRequired, true))
+	assert.Equal(t, "value", colorize("value", colorRequired, false))
+	assert.Equal(t, "", colorize("", colorRequired, true))
+}
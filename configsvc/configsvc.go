@@ -0,0 +1,149 @@
+// Package configsvc implements a source reading values from a central configuration service.
+//
+// The service has to implement the following HTTP/JSON protocol:
+//
+// Looking up a single value:
+//
+//	GET /v1/values/{key}
+//
+//	200 OK
+//	{"value": "..."}
+//
+// Missing keys are reported with a 404 Not Found status code.
+//
+// Watching values for changes:
+//
+//	GET /v1/watch?key={key}&key={key}
+//
+//	200 OK
+//	{"key": "...", "value": "..."}
+//	{"key": "...", "deleted": true}
+//
+// The response of a watch request is a stream of newline delimited JSON events,
+// sent whenever one of the watched keys changes. The service keeps the connection open until the client closes it.
+//
+// Only the HTTP/JSON transport is implemented. There is no gRPC client, since it would add gRPC and generated
+// protobuf code to the dependencies of every user; services speaking gRPC can expose the protocol through a JSON gateway.
+package configsvc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Event is a change of a watched value.
+type Event struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+// value is the response of a value lookup.
+type value struct {
+	Value string `json:"value"`
+}
+
+// Source looks up values from a configuration service.
+type Source struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewSource returns a new Source for the service located at baseURL (eg. https://config.example.com).
+func NewSource(baseURL string) *Source {
+	return &Source{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  http.DefaultClient,
+	}
+}
+
+// SetHTTPClient sets the HTTP client used for contacting the service (eg. for authentication).
+func (s *Source) SetHTTPClient(client *http.Client) {
+	s.client = client
+}
+
+// Lookup implements the nest.Source interface.
+func (s *Source) Lookup(key string) (string, bool, error) {
+	return s.LookupContext(context.Background(), key)
+}
+
+// LookupContext implements the nest.ContextSource interface.
+func (s *Source) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	req, err := http.NewRequest("GET", s.baseURL+"/v1/values/"+url.PathEscape(key), nil)
+	if err != nil {
+		return "", false, err
+	}
+
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	} else if resp.StatusCode != http.StatusOK {
+		return "", false, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var v value
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return "", false, err
+	}
+
+	return v.Value, true, nil
+}
+
+// Watch calls fn for every change of the watched keys.
+// It blocks until the context is cancelled or the service closes the stream.
+func (s *Source) Watch(ctx context.Context, keys []string, fn func(event Event)) error {
+	query := url.Values{}
+	for _, key := range keys {
+		query.Add("key", key)
+	}
+
+	req, err := http.NewRequest("GET", s.baseURL+"/v1/watch?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var event Event
+		if err := json.Unmarshal(line, &event); err != nil {
+			return err
+		}
+
+		fn(event)
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,99 @@
+package configsvc_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/goph/nest/configsvc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newServer() *httptest.Server {
+	values := map[string]string{
+		"Database.Host": "db.example.com",
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/values/", func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/v1/values/")
+
+		if key == "Broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		value, ok := values[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		fmt.Fprintf(w, `{"value":%q}`, value)
+	})
+	mux.HandleFunc("/v1/watch", func(w http.ResponseWriter, r *http.Request) {
+		for _, key := range r.URL.Query()["key"] {
+			fmt.Fprintf(w, "{\"key\":%q,\"value\":\"changed\"}\n", key)
+		}
+
+		fmt.Fprintln(w, `{"key":"Removed","deleted":true}`)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestSource(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+
+	type config struct {
+		Database struct {
+			Host string
+			Port int `default:"3306"`
+		}
+	}
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.AddSource(configsvc.NewSource(server.URL + "/"))
+
+	err := configurator.Load(&c)
+	require.NoError(t, err)
+	assert.Equal(t, "db.example.com", c.Database.Host)
+	assert.Equal(t, 3306, c.Database.Port)
+}
+
+func TestSource_Error(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+
+	_, _, err := configsvc.NewSource(server.URL).Lookup("Broken")
+	assert.EqualError(t, err, "unexpected status code 500")
+}
+
+func TestSource_Watch(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+
+	var events []configsvc.Event
+
+	err := configsvc.NewSource(server.URL).Watch(context.Background(), []string{"Database.Host"}, func(event configsvc.Event) {
+		events = append(events, event)
+	})
+	require.NoError(t, err)
+
+	expected := []configsvc.Event{
+		{Key: "Database.Host", Value: "changed"},
+		{Key: "Removed", Deleted: true},
+	}
+	assert.Equal(t, expected, events)
+}
@@ -2,11 +2,14 @@ package nest
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -16,323 +19,2145 @@ import (
 	"github.com/spf13/viper"
 )
 
-var (
-	// ErrNotStructPointer is returned when value passed to config.Load() is not a pointer to a struct.
-	ErrNotStructPointer = errors.New("value passed is not a struct pointer")
+// FlagAnnotationChoices is the flag annotation holding the allowed values of a field configured with the enum tag.
+const FlagAnnotationChoices = "nest_annotation_choices"
 
-	// ErrNotStruct is returned when value passed to config.Load() is not a struct.
-	ErrNotStruct = errors.New("value passed is not a struct")
+// NameCase is a naming convention of generated names.
+type NameCase int
 
-	// ErrFlagHelp is returned when the commandline arguments include -h or --help.
-	// Application should exit without an error as pflag handles outputting the manual.
-	ErrFlagHelp = pflag.ErrHelp
+const (
+	// SnakeEnv generates snake cased environment variable names (eg. MAX_IDLE_CONNS).
+	SnakeEnv NameCase = 1 << iota
+
+	// SpinalFlags generates spinal cased flag names (eg. max-idle-conns).
+	SpinalFlags
+)
+
+// UsageOrder controls the order of the entries in each section of the help output.
+//
+// Regardless of the order, entries with a higher weight (configured with the weight tag) are displayed first.
+type UsageOrder int
+
+const (
+	// UsageOrderDeclaration displays entries in the order of declaration.
+	UsageOrderDeclaration UsageOrder = iota
+
+	// UsageOrderAlphabetical displays entries in alphabetical order.
+	UsageOrderAlphabetical
+)
+
+// PrefillMode controls how values pre-populated in the configuration struct are treated.
+type PrefillMode int
+
+const (
+	// PrefillOverride makes pre-populated values take precedence over every source (default).
+	PrefillOverride PrefillMode = iota
+
+	// PrefillMerge merges resolved values over pre-populated ones:
+	// pre-populated values are only kept for fields which are not set by flags, environment variables or sources,
+	// but they still take precedence over defaults.
+	PrefillMerge
 )
 
-func NewConfigurator() *Configurator {
-	return &Configurator{
-		args:  os.Args,
-		viper: viper.New(),
+func NewConfigurator() *Configurator {
+	return &Configurator{
+		args:  os.Args,
+		viper: viper.New(),
+
+		earlyHelp: true,
+	}
+}
+
+type Configurator struct {
+	// Used when displaying help
+	name string
+
+	// Command line arguments (defaults to os.Args)
+	args []string
+
+	// Flag parsing behavior
+	flagErrorHandling  pflag.ErrorHandling
+	ignoreUnknownFlags bool
+	allowUnknownFlags  bool
+	slashFlags         bool
+
+	// Flag prefix
+	flagPrefix string
+
+	// Display the help before looking up sources
+	earlyHelp bool
+
+	// Meta flags
+	version            string
+	validateConfigFlag bool
+	configFlag         bool
+
+	// Match environment variable names case-insensitively
+	envCaseInsensitive bool
+
+	// Fail when a flag and an environment variable set different values
+	strictConflicts bool
+
+	// Trim surrounding whitespace from values
+	trimSpace bool
+
+	// Development mode (see DevMode)
+	devMode bool
+
+	// Mode the application runs in (eg. production)
+	mode string
+
+	// Write every load error to the error output instead of letting pflag print it's own
+	printErrors bool
+
+	// Displays load errors instead of the built-in format (see SetErrorRenderer)
+	errorRenderer ErrorRenderer
+
+	// Structured logging of the load results (see SetSlogLogger)
+	logLoad func(ctx context.Context, report *Report, err error)
+
+	// Arguments not consumed during the last Load
+	restArgs []string
+
+	// Environment prefix
+	envPrefix string
+
+	// Derive the environment prefix from the name when it's not set
+	envPrefixFromName bool
+
+	// Fallback environment prefixes (eg. before renaming the application)
+	legacyEnvPrefixes []string
+
+	// Treatment of pre-populated values
+	prefillMode PrefillMode
+
+	// Configuration struct size limits
+	limits definitionLimits
+
+	// Split words in field names by default
+	splitWords bool
+
+	// Merge the fields of embedded structs into the parent namespace
+	mergeEmbedded bool
+
+	// Check struct tags for mistakes before loading
+	strictTags  bool
+	allowedTags map[string]bool
+
+	// Naming conventions of generated names
+	defaultCase NameCase
+
+	// Delimiter joining the keys of nested structs
+	keyDelimiter string
+
+	// Honor kelseyhightower/envconfig tags and semantics
+	envconfigCompat bool
+
+	// Additional configuration sources along with their priorities
+	sources []prioritizedSource
+
+	// File in dotenv format adding variables to the environment (see SetEnvFile)
+	envFile string
+
+	// Configuration file (see SetConfigFile) and the directories it's searched in (see AddConfigPath)
+	configFile  string
+	configPaths []string
+
+	// Configuration file chosen by the --config flag or the CONFIG environment variable during Load
+	configFileArg string
+
+	// Number of concurrent source lookups
+	sourceConcurrency int
+
+	// Time limit for decoding a single field
+	decodeTimeout time.Duration
+
+	// Time limit for loading the whole configuration
+	loadTimeout time.Duration
+
+	// Decryptors for encrypted values keyed by scheme
+	decryptors map[string]Decryptor
+
+	// Hooks transforming raw values before processing
+	fieldHooks []FieldHook
+	coercer    Coercer
+
+	beforeLoadHooks []func(schema *Schema)
+	afterLoadHooks  []func(config interface{}, report *Report)
+
+	translator Translator
+	colorMode  ColorMode
+	usageOrder UsageOrder
+
+	shortEnvUsage bool
+
+	viper       *viper.Viper
+	flags       *pflag.FlagSet
+	output      io.Writer
+	errorOutput io.Writer
+
+	// Flags registered for fields by name (the flag set is kept between loads)
+	flagBindings map[string]flagBinding
+
+	// Snapshot of the environment taken at the start of Load (nil outside of Load)
+	environ map[string]string
+
+	mu sync.Mutex
+}
+
+// SetEnvPrefix manually sets the environment variable prefix.
+func (c *Configurator) SetEnvPrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.envPrefix = prefix
+	c.legacyEnvPrefixes = nil
+	c.viper.SetEnvPrefix(prefix)
+}
+
+// SetFlagPrefix sets a prefix prepended to every flag name (eg. "myapp" turns --value into --myapp-value).
+func (c *Configurator) SetFlagPrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.flagPrefix = prefix
+}
+
+// SetEnvPrefixes sets the environment variable prefix along with fallback prefixes.
+// Variables with a fallback prefix are only used when the one with the first prefix is not set
+// and a warning is displayed when they are (eg. during a migration after renaming the application).
+func (c *Configurator) SetEnvPrefixes(prefix string, legacyPrefixes ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.envPrefix = prefix
+	c.legacyEnvPrefixes = legacyPrefixes
+	c.viper.SetEnvPrefix(prefix)
+}
+
+// SetEnvPrefixFromName enables deriving the environment variable prefix from the application name
+// (eg. "my service" becomes "MY_SERVICE") when no prefix is set manually.
+func (c *Configurator) SetEnvPrefixFromName(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.envPrefixFromName = enabled
+}
+
+// SetName sets the application name for displaying help.
+func (c *Configurator) SetName(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.name = name
+}
+
+// SetArgs sets the command line arguments.
+func (c *Configurator) SetArgs(args []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.args = args
+}
+
+// SetFlagErrorHandling sets the error handling mode of the flag set (defaults to pflag.ContinueOnError).
+func (c *Configurator) SetFlagErrorHandling(errorHandling pflag.ErrorHandling) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.flagErrorHandling = errorHandling
+}
+
+// SetIgnoreUnknownFlags makes flag parsing tolerate flags which are not registered (eg. flags of other libraries).
+func (c *Configurator) SetIgnoreUnknownFlags(ignore bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ignoreUnknownFlags = ignore
+}
+
+// SetSlashFlags enables accepting Windows style flags (eg. /flag:value or /flag).
+// Only arguments matching a registered flag are converted, so paths are left untouched.
+func (c *Configurator) SetSlashFlags(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.slashFlags = enabled
+}
+
+// SetEnvCaseInsensitive enables matching environment variable names case-insensitively (as on Windows).
+func (c *Configurator) SetEnvCaseInsensitive(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.envCaseInsensitive = enabled
+}
+
+// AllowUnknownFlags makes Load ignore flags it did not register instead of failing.
+// Unknown flags are collected along with positional arguments and can be retrieved by calling Args.
+func (c *Configurator) AllowUnknownFlags() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.allowUnknownFlags = true
+}
+
+// Args returns the arguments which were not consumed during the last Load (unknown flags and positional arguments).
+func (c *Configurator) Args() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.restArgs
+}
+
+// SetPrefillMode sets how values pre-populated in the configuration struct are treated (defaults to PrefillOverride).
+func (c *Configurator) SetPrefillMode(mode PrefillMode) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.prefillMode = mode
+}
+
+// SetMaxDepth limits how deep structs can be nested in the configuration struct (zero means no limit).
+func (c *Configurator) SetMaxDepth(depth int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.limits.maxDepth = depth
+}
+
+// SetMaxFields limits the number of fields in the configuration struct (zero means no limit).
+func (c *Configurator) SetMaxFields(fields int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.limits.maxFields = fields
+}
+
+// SetSplitWords enables splitting words in field names (eg. for flag and environment variable names) for every field.
+// It can still be disabled for individual fields (and their children) with the split_words tag.
+func (c *Configurator) SetSplitWords(splitWords bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.splitWords = splitWords
+}
+
+// SetEarlyHelp controls whether the help is displayed before looking up values (enabled by default),
+// so that --help works even when sources are unreachable or required values are missing.
+//
+// When disabled, the help is displayed while parsing flags (after looking up sources).
+func (c *Configurator) SetEarlyHelp(earlyHelp bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.earlyHelp = earlyHelp
+}
+
+// SetVersion sets the version of the application displayed by the --version flag.
+// The flag is only available when a version is set.
+func (c *Configurator) SetVersion(version string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version = version
+}
+
+// SetValidateConfigFlag enables the --validate-config flag,
+// which loads the configuration and reports whether it is valid instead of running the application.
+func (c *Configurator) SetValidateConfigFlag(validateConfigFlag bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.validateConfigFlag = validateConfigFlag
+}
+
+// SetConfigFlag enables the --config flag and the CONFIG environment variable (with the environment variable prefix),
+// which set the configuration file to read, taking precedence over SetConfigFile and AddConfigPath.
+// The flag takes precedence over the environment variable.
+func (c *Configurator) SetConfigFlag(configFlag bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.configFlag = configFlag
+}
+
+// SetStrictConflicts makes Load fail when both a flag and an environment variable set a field to different values
+// instead of silently resolving the conflict in favor of the flag.
+func (c *Configurator) SetStrictConflicts(strictConflicts bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.strictConflicts = strictConflicts
+}
+
+// SetTrimSpace enables stripping leading and trailing whitespace (including trailing newlines) from every value.
+// It's useful when values are read from mounted secret files, which usually end with a newline.
+// Trimming can be enabled or disabled for individual fields with the trim tag.
+func (c *Configurator) SetTrimSpace(trimSpace bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.trimSpace = trimSpace
+}
+
+// SetStrictTags enables checking the struct tags of the configuration struct for mistakes before loading:
+// unknown tags, contradictory combinations (eg. required and ignored) and default values which cannot be parsed.
+//
+// Only keys resembling the tags of the package (eg. defualt, env_name) are reported as unknown,
+// tags of other libraries (eg. json, yaml, validate) are left alone. Registered custom tags are accepted,
+// keys mistaken for a misspelled tag can be allowed explicitly.
+func (c *Configurator) SetStrictTags(strictTags bool, allowed ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.strictTags = strictTags
+	c.allowedTags = make(map[string]bool, len(allowed))
+
+	for _, tag := range allowed {
+		c.allowedTags[tag] = true
+	}
+}
+
+// SetMergeEmbedded merges the fields of embedded structs into the namespace of the embedding struct
+// (eg. VALUE instead of SUBCONFIG_VALUE), the same way Go promotes them.
+// Embedded structs having a prefix tag are not affected.
+func (c *Configurator) SetMergeEmbedded(mergeEmbedded bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.mergeEmbedded = mergeEmbedded
+}
+
+// SetDefaultCase sets the naming conventions of generated environment variable and flag names.
+// It can still be disabled for individual fields with the split_words tag.
+func (c *Configurator) SetDefaultCase(cases ...NameCase) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.defaultCase = 0
+	for _, nameCase := range cases {
+		c.defaultCase |= nameCase
+	}
+}
+
+// SetKeyDelimiter sets the delimiter joining the keys of nested structs (defaults to ".").
+// Changing it is necessary when field names or keys in configuration files contain dots.
+func (c *Configurator) SetKeyDelimiter(delimiter string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.keyDelimiter = delimiter
+
+	c.viper = viper.NewWithOptions(viper.KeyDelimiter(delimiter))
+	c.viper.SetEnvPrefix(c.envPrefix)
+}
+
+// CompatEnvconfig enables compatibility with kelseyhightower/envconfig for migrating projects incrementally.
+//
+// In compatibility mode every field can be set from an environment variable (unless ignored),
+// the envconfig tag sets the name of the variable and the desc tag is used as usage.
+func (c *Configurator) CompatEnvconfig() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.envconfigCompat = true
+}
+
+// SetMode sets the mode the application runs in (eg. production or development).
+// Mode specific tags (eg. required:"production") are matched against it case-insensitively.
+func (c *Configurator) SetMode(mode string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.mode = mode
+}
+
+// DevMode makes loading the configuration forgiving for local development:
+// missing required values are reported as warnings instead of errors
+// and an annotated summary of the loaded values (with their origin) is written to the error output.
+//
+// It should not be enabled in production, where misconfigurations should be fatal.
+func (c *Configurator) DevMode() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.devMode = true
+}
+
+// AddSource appends a source with PrioritySource to the list of sources values are looked up from.
+// Sources with the same priority are queried in the order they were added.
+func (c *Configurator) AddSource(source Source) {
+	c.AddSourceWithPriority(source, PrioritySource)
+}
+
+// AddSourceWithPriority appends a source with the given priority to the list of sources values are looked up from.
+//
+// Sources with higher priority are consulted first. The priority also slots the source relative to the built-in origins:
+// a source with a priority above PriorityEnv takes precedence over environment variables (and values resolved by tags),
+// above PriorityFlag over flags as well. Overrides (pre-populated values) always take precedence.
+// Ties with a built-in origin are resolved in favor of the built-in origin.
+//
+// The priority can only be set when the source is added: sources cannot be identified by their value later
+// (uncomparable sources cannot be compared and equal sources cannot be told apart).
+func (c *Configurator) AddSourceWithPriority(source Source, priority int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sources = append(c.sources, prioritizedSource{Source: source, priority: priority})
+}
+
+// SetSourceConcurrency sets the number of keys looked up concurrently in the sources (defaults to 1).
+func (c *Configurator) SetSourceConcurrency(concurrency int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sourceConcurrency = concurrency
+}
+
+// SetDecodeTimeout limits the time a DecoderContext implementation can spend on decoding a single field (zero means no limit).
+func (c *Configurator) SetDecodeTimeout(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.decodeTimeout = timeout
+}
+
+// SetLoadTimeout limits the total time Load can spend on resolving the configuration (zero means no limit),
+// so that a stalled backend cannot hang the startup of the application.
+//
+// The time limit is enforced even for sources not supporting cancellation (their lookups are abandoned).
+// Exceeding it results in a *LoadTimeoutError listing the fields which were still unresolved.
+func (c *Configurator) SetLoadTimeout(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.loadTimeout = timeout
+}
+
+// SetDecryptor registers a decryptor for values prefixed with the given scheme (eg. "kms" for "kms:ciphertext").
+func (c *Configurator) SetDecryptor(scheme string, decryptor Decryptor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.decryptors == nil {
+		c.decryptors = make(map[string]Decryptor)
+	}
+
+	c.decryptors[scheme] = decryptor
+}
+
+// AddFieldHook appends a hook to the list of hooks executed on every raw value before it is processed.
+// Hooks are executed in the order they were added.
+func (c *Configurator) AddFieldHook(hook FieldHook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fieldHooks = append(c.fieldHooks, hook)
+}
+
+// SetOutput sets the output writer used for help text and error messages.
+// Error messages can be written to a separate writer by calling SetErrorOutput.
+//
+// By default explicitly requested help is written to STDOUT,
+// while error messages (and the help displayed after them) are written to STDERR.
+func (c *Configurator) SetOutput(output io.Writer) {
+	c.output = output
+}
+
+// SetErrorOutput sets the output writer used for error messages and warnings.
+func (c *Configurator) SetErrorOutput(output io.Writer) {
+	c.errorOutput = output
+}
+
+// SetPrintErrors enables writing every problem preventing the configuration from being loaded
+// (flag parsing errors, missing required values, validation errors, etc.) to the error output in a uniform format:
+//
+//	Error: required field Host missing value
+//
+// Errors are still returned by Load. In this mode the flag set doesn't print errors or usage on it's own.
+func (c *Configurator) SetPrintErrors(printErrors bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.printErrors = printErrors
+}
+
+// ErrorRenderer displays an error preventing the configuration from being loaded.
+//
+// The error is the one returned by Load: multiple problems are reported as Errors,
+// the details of specific failures can be extracted with As (eg. *LoadTimeoutError).
+type ErrorRenderer func(err error, w io.Writer)
+
+// SetErrorRenderer sets a function displaying load errors on the error output (eg. as JSON or localized text)
+// instead of the format used by SetPrintErrors. Setting a renderer enables printing errors.
+func (c *Configurator) SetErrorRenderer(renderer ErrorRenderer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.errorRenderer = renderer
+}
+
+// OnBeforeLoad registers a hook called by Load before resolving the values.
+// Hooks can modify the schema (eg. add defaults), but they must not call the configurator.
+func (c *Configurator) OnBeforeLoad(hook func(schema *Schema)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.beforeLoadHooks = append(c.beforeLoadHooks, hook)
+}
+
+// OnAfterLoad registers a hook called after the configuration is successfully loaded.
+// Hooks must not call the configurator.
+func (c *Configurator) OnAfterLoad(hook func(config interface{}, report *Report)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.afterLoadHooks = append(c.afterLoadHooks, hook)
+}
+
+// SetCoercer sets a function building field values from raw values before the default conversion logic.
+func (c *Configurator) SetCoercer(coercer Coercer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.coercer = coercer
+}
+
+// SetTranslator sets a function used for localizing help and error messages.
+func (c *Configurator) SetTranslator(translator Translator) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.translator = translator
+}
+
+// SetColorMode sets whether the help output should be colored (defaults to ColorNever).
+func (c *Configurator) SetColorMode(mode ColorMode) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.colorMode = mode
+}
+
+// SetUsageOrder sets the order of the entries in the help output.
+func (c *Configurator) SetUsageOrder(order UsageOrder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.usageOrder = order
+}
+
+// SetShortEnvUsage sets whether the help output should display environment variables without the prefix.
+//
+// By default the fully-qualified names (that have to be set by operators) are displayed.
+func (c *Configurator) SetShortEnvUsage(short bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.shortEnvUsage = short
+}
+
+// translate returns the localized version of a message using the configured translator.
+func (c *Configurator) translate(msgID string, args ...interface{}) string {
+	if c.translator == nil {
+		return defaultTranslator(msgID, args...)
+	}
+
+	return c.translator(msgID, args...)
+}
+
+// out returns the configured output or the default which is STDOUT.
+func (c *Configurator) out() io.Writer {
+	if c.output == nil {
+		return os.Stdout
+	}
+
+	return c.output
+}
+
+// errOut returns the configured error output.
+// It defaults to the regular output if that is configured, to STDERR otherwise.
+func (c *Configurator) errOut() io.Writer {
+	if c.errorOutput != nil {
+		return c.errorOutput
+	}
+
+	if c.output != nil {
+		return c.output
+	}
+
+	return os.Stderr
+}
+
+// mergeWithEnvPrefix merges an environment variable alias with the configured prefix.
+// The code bellow is from Viper's source.
+func (c *Configurator) mergeWithEnvPrefix(in string) string {
+	envPrefix := c.envPrefix
+	if envPrefix == "" && c.envPrefixFromName {
+		envPrefix = envPrefixFromName(c.name)
+	}
+
+	if envPrefix != "" {
+		return strings.ToUpper(envPrefix + "_" + in)
+	}
+
+	return strings.ToUpper(in)
+}
+
+// mergeWithFlagPrefix merges a flag alias with the configured prefix.
+func (c *Configurator) mergeWithFlagPrefix(in string) string {
+	if c.flagPrefix != "" {
+		return c.flagPrefix + "-" + in
+	}
+
+	return in
+}
+
+// lookupEnvName returns the name of the environment variable to read for an alias.
+// If the variable with the configured prefix is not set, the legacy prefixes and the additional aliases are tried in order.
+// The boolean return value reports whether the name has a legacy prefix.
+func (c *Configurator) lookupEnvName(alias string, aliases ...string) (string, bool) {
+	name := c.mergeWithEnvPrefix(alias)
+	if actualName, ok := c.lookupEnv(name); ok {
+		return actualName, false
+	}
+
+	for _, prefix := range c.legacyEnvPrefixes {
+		legacyName := strings.ToUpper(prefix + "_" + alias)
+		if actualName, ok := c.lookupEnv(legacyName); ok {
+			return actualName, true
+		}
+	}
+
+	for _, alias := range aliases {
+		if actualName, ok := c.lookupEnv(c.mergeWithEnvPrefix(alias)); ok {
+			return actualName, false
+		}
+	}
+
+	return name, false
+}
+
+// warn writes a warning to the error output and records it in the report.
+func (c *Configurator) warn(report *Report, format string, args ...interface{}) {
+	warning := fmt.Sprintf(format, args...)
+
+	fmt.Fprintf(c.errOut(), "Warning: %s\n", warning)
+
+	report.Warnings = append(report.Warnings, warning)
+}
+
+// printError writes an error (or every error of a list) to the error output using the error renderer (if any).
+func (c *Configurator) printError(err error) {
+	if c.errorRenderer != nil {
+		c.errorRenderer(err, c.errOut())
+
+		return
+	}
+
+	errs, ok := err.(Errors)
+	if !ok {
+		errs = Errors{err}
+	}
+
+	for _, err := range errs {
+		fmt.Fprintf(c.errOut(), "Error: %s\n", err)
+	}
+}
+
+// lookupEnv checks whether an environment variable is set and returns it's actual name.
+func (c *Configurator) lookupEnv(name string) (string, bool) {
+	environ := c.environ
+	if environ == nil {
+		environ = snapshotEnv()
+	}
+
+	if _, ok := environ[name]; ok {
+		return name, true
+	}
+
+	if c.envCaseInsensitive {
+		for envName := range environ {
+			if strings.EqualFold(envName, name) {
+				return envName, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// getenv returns the value of an environment variable (from the snapshot during Load).
+func (c *Configurator) getenv(name string) string {
+	if c.environ == nil {
+		return os.Getenv(name)
+	}
+
+	return c.environ[name]
+}
+
+// snapshotEnv captures the current environment.
+func snapshotEnv() map[string]string {
+	environ := os.Environ()
+	snapshot := make(map[string]string, len(environ))
+
+	for _, env := range environ {
+		pair := strings.SplitN(env, "=", 2)
+		if len(pair) == 2 {
+			snapshot[pair[0]] = pair[1]
+		}
+	}
+
+	return snapshot
+}
+
+// Load loads the configuration into a struct.
+func (c *Configurator) Load(config interface{}) error {
+	return c.LoadContext(context.Background(), config)
+}
+
+// LoadContext is the same as Load, but it accepts a context which is passed to the sources.
+func (c *Configurator) LoadContext(ctx context.Context, config interface{}) error {
+	_, err := c.LoadWithReportContext(ctx, config)
+
+	return err
+}
+
+// LoadPrefix loads the configuration keys under a prefix (eg. database) into a struct,
+// as if the struct was a field of the configuration with the given prefix tag.
+// It lets subsystems load their part of the configuration on first use.
+//
+// Every source is consulted, flags of keys outside the prefix are unknown unless they were loaded before
+// (or unknown flags are ignored).
+func (c *Configurator) LoadPrefix(prefix string, config interface{}) error {
+	return c.LoadPrefixContext(context.Background(), prefix, config)
+}
+
+// LoadPrefixContext is the same as LoadPrefix, but it accepts a context which is passed to the sources.
+func (c *Configurator) LoadPrefixContext(ctx context.Context, prefix string, config interface{}) error {
+	if _, err := getTarget(config); err != nil {
+		return err
+	}
+
+	ptr := reflect.ValueOf(config)
+
+	// The target is loaded in place through a pointer field carrying the prefix
+	wrapper := reflect.New(reflect.StructOf([]reflect.StructField{
+		{
+			Name: "Config",
+			Type: ptr.Type(),
+			Tag:  reflect.StructTag(TagPrefix + ":" + strconv.Quote(prefix)),
+		},
+	}))
+	wrapper.Elem().Field(0).Set(ptr)
+
+	return c.LoadContext(ctx, wrapper.Interface())
+}
+
+// LoadWithReport is the same as Load, but it also returns a report describing where the values came from.
+func (c *Configurator) LoadWithReport(config interface{}) (*Report, error) {
+	return c.LoadWithReportContext(context.Background(), config)
+}
+
+// LoadWithReportContext is the same as LoadWithReport, but it accepts a context which is passed to the sources.
+func (c *Configurator) LoadWithReportContext(ctx context.Context, config interface{}) (*Report, error) {
+	elem, err := getTarget(config)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.name == "" {
+		c.name = c.args[0]
+	}
+
+	definitions, err := c.getDefinitions(elem)
+	if err != nil {
+		return nil, err
+	}
+
+	// Let the hooks modify the schema
+	if len(c.beforeLoadHooks) > 0 {
+		schema := newSchema(c, elem, definitions)
+
+		for _, hook := range c.beforeLoadHooks {
+			hook(schema)
+		}
+
+		definitions = schema.definitions()
+	}
+
+	return c.load(ctx, elem, definitions, c.sources)
+}
+
+// BindDefinitions registers the defaults, environment variables and flags of a configuration struct in a Viper instance
+// without loading any values, so that the struct can be populated with viper.Unmarshal.
+//
+// Flags are registered in the flag set returned by Flags, parsing them is the responsibility of the caller.
+func (c *Configurator) BindDefinitions(v *viper.Viper, config interface{}) error {
+	elem, err := getTarget(config)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	definitions, err := c.getDefinitions(elem)
+	if err != nil {
+		return err
+	}
+
+	flags := c.getFlags()
+
+	for _, def := range definitions {
+		if def.hasFlag {
+			flag, err := c.defineFlag(flags, def)
+			if err != nil {
+				return err
+			}
+
+			if err := v.BindPFlag(def.key, flag); err != nil {
+				return err
+			}
+		}
+
+		if def.hasEnv {
+			envName, _ := c.lookupEnvName(def.envAlias, def.envAliases...)
+
+			if err := v.BindEnv(def.key, envName); err != nil {
+				return err
+			}
+		}
+
+		if def.hasDefault {
+			v.SetDefault(def.key, def.defaultValue)
+		}
+	}
+
+	return nil
+}
+
+// flagBinding records the field a flag was registered for.
+type flagBinding struct {
+	key    string
+	isBool bool
+}
+
+// defineFlag registers the flag of a field in the flag set (unless it's already registered) and returns it.
+//
+// The flag set is kept between loads: flags registered by a previous load are reset, so that their values don't leak
+// into the next one. It's an error if the flag was registered for a different field or type (eg. the schema changed).
+func (c *Configurator) defineFlag(flags *pflag.FlagSet, def fieldDefinition) (*pflag.Flag, error) {
+	flagName := c.mergeWithFlagPrefix(def.flagAlias)
+	isBool := def.field.Kind() == reflect.Bool
+
+	if flag := flags.Lookup(flagName); flag != nil {
+		binding, ok := c.flagBindings[flagName]
+
+		// Flags registered by the application are used as is
+		if !ok {
+			return flag, nil
+		}
+
+		if binding.key != def.key {
+			return nil, fmt.Errorf("flag --%s is already bound to field %s, cannot bind it to %s", flagName, binding.key, def.key)
+		}
+
+		if binding.isBool != isBool {
+			return nil, fmt.Errorf("flag --%s was registered for a field of different type, the type of %s changed", flagName, def.key)
+		}
+
+		if err := flag.Value.Set(flag.DefValue); err != nil {
+			return nil, err
+		}
+
+		flag.Changed = false
+
+		return flag, nil
+	}
+
+	// Bool flags can be supplied without a value
+	if isBool {
+		flags.VarPF(new(boolValue), flagName, "", def.usage).NoOptDefVal = "true"
+	} else {
+		flags.String(flagName, "", def.usage)
+	}
+
+	if c.flagBindings == nil {
+		c.flagBindings = make(map[string]flagBinding)
+	}
+
+	c.flagBindings[flagName] = flagBinding{key: def.key, isBool: isBool}
+
+	return flags.Lookup(flagName), nil
+}
+
+// load loads the configuration values described by the definitions into a struct and calls the after load hooks.
+func (c *Configurator) load(ctx context.Context, elem reflect.Value, definitions []fieldDefinition, sources []prioritizedSource) (*Report, error) {
+	start := time.Now()
+
+	// Environment variables are resolved from a single snapshot,
+	// so that concurrent changes cannot result in a partially updated configuration
+	c.environ = snapshotEnv()
+	defer func() { c.environ = nil }()
+
+	report := &Report{Env: make(map[string]string)}
+
+	// Meta flags are handled before anything else, so that they always work
+	meta := c.bootstrap(c.getFlags())
+
+	if meta.help {
+		c.writeUsage(c.out(), definitions)
+
+		return nil, ErrFlagHelp
+	}
+
+	if meta.version {
+		fmt.Fprintln(c.out(), c.translate(MsgVersion, c.name, c.version))
+
+		return nil, ErrFlagVersion
+	}
+
+	if c.loadTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, c.loadTimeout)
+		defer cancel()
+	}
+
+	var err error
+
+	// Variables of the env file are part of the snapshot
+	if c.envFile != "" {
+		err = mergeEnvFile(c.environ, c.envFile)
+	}
+
+	// The configuration file can be chosen by the --config flag or the CONFIG environment variable
+	if c.configFlag {
+		c.configFileArg = meta.configFile
+		if c.configFileArg == "" {
+			c.configFileArg = c.getenv(c.mergeWithEnvPrefix(envConfig))
+		}
+
+		defer func() { c.configFileArg = "" }()
+	}
+
+	if err == nil {
+		sources := c.withConfigFile(sources)
+
+		err = c.loadValues(ctx, elem, definitions, sources, report)
+
+		// Failing to persist the state of a source (eg. a cache) does not affect the loaded values
+		for _, ferr := range flushSources(c.sourcesByPriority(sources)) {
+			c.warn(report, "failed to flush source: %v", ferr)
+		}
+	}
+
+	// Anything interrupted by the time limit is reported uniformly
+	if err != nil && c.loadTimeout > 0 && ctx.Err() == context.DeadlineExceeded && !Is(err, ErrLoadTimeout) {
+		err = &LoadTimeoutError{Timeout: c.loadTimeout}
+	}
+
+	report.Duration = time.Since(start)
+
+	if c.logLoad != nil {
+		c.logLoad(ctx, report, err)
+	}
+
+	if err != nil {
+		if c.printErrors || c.errorRenderer != nil {
+			c.printError(err)
+		}
+
+		return nil, err
+	}
+
+	for _, hook := range c.afterLoadHooks {
+		hook(elem.Addr().Interface(), report)
+	}
+
+	if c.devMode {
+		writeSummary(c.errOut(), report)
+	}
+
+	if meta.validateConfig {
+		fmt.Fprintln(c.out(), c.translate(MsgConfigValid))
+
+		return nil, ErrConfigValid
+	}
+
+	return report, nil
+}
+
+// loadValues loads the configuration values described by the definitions into a struct.
+func (c *Configurator) loadValues(ctx context.Context, elem reflect.Value, definitions []fieldDefinition, sources []prioritizedSource, report *Report) error {
+	flags := c.getFlags()
+	flags.ParseErrorsWhitelist.UnknownFlags = c.ignoreUnknownFlags
+
+	// pflag writes parse errors to the output before displaying usage,
+	// so usage following an error can be sent to the error output
+	flagOutput := &trackingWriter{w: c.errOut()}
+	flags.SetOutput(flagOutput)
+
+	// The meta flags are extracted in the bootstrap phase, but they have to be accepted here as well
+	if c.validateConfigFlag && flags.Lookup(flagValidateConfig) == nil {
+		flags.Bool(flagValidateConfig, false, "")
+		flags.SetAnnotation(flagValidateConfig, flagAnnotationMeta, []string{"true"})
+	}
+
+	if c.configFlag && flags.Lookup(flagConfig) == nil {
+		flags.String(flagConfig, "", "")
+		flags.SetAnnotation(flagConfig, flagAnnotationMeta, []string{"true"})
+	}
+
+	// Flags registered by the application should be parsed as well
+	parseFlags := flags.HasFlags()
+
+	flags.Usage = func() {
+		out := c.out()
+		if flagOutput.written {
+			out = c.errOut()
+		}
+
+		c.writeUsage(out, definitions)
+	}
+
+	// Errors are printed by Load instead
+	if c.printErrors || c.errorRenderer != nil {
+		flags.SetOutput(ioutil.Discard)
+		flags.Usage = func() {}
+	}
+
+	// Look up the values in the sources (if any), lazy fields are looked up on first use
+	var keys []string
+	var keyIndexes []int
+
+	for i, def := range definitions {
+		if def.field.Type() != lazyType {
+			keys = append(keys, def.key)
+			keyIndexes = append(keyIndexes, i)
+		}
+	}
+
+	// Sources with a priority above environment variables or flags are looked up separately
+	below, aboveEnv, aboveFlag := c.sortSources(sources)
+
+	aboveEnvValues, err := lookupAllSources(ctx, aboveEnv, keys, c.sourceConcurrency)
+	if err != nil {
+		return c.lookupError(err, keys)
+	}
+
+	aboveFlagValues, err := lookupAllSources(ctx, aboveFlag, keys, c.sourceConcurrency)
+	if err != nil {
+		return c.lookupError(err, keys)
+	}
+
+	values, err := lookupAllSources(ctx, below, keys, c.sourceConcurrency)
+	if err != nil {
+		return c.lookupError(err, keys)
+	}
+
+	sourceValues := make([]sourceValue, len(definitions))
+	for j, value := range values {
+		sourceValues[keyIndexes[j]] = value
+	}
+
+	// Fall back to the former keys of the fields missing from the sources
+	var aliasKeys []string
+	var aliasIndexes []int
+
+	for i, def := range definitions {
+		if !sourceValues[i].found && def.field.Type() != lazyType {
+			for _, alias := range def.keyAliases {
+				aliasKeys = append(aliasKeys, alias)
+				aliasIndexes = append(aliasIndexes, i)
+			}
+		}
+	}
+
+	if len(aliasKeys) > 0 {
+		aliasValues, err := lookupAllSources(ctx, below, aliasKeys, c.sourceConcurrency)
+		if err != nil {
+			fieldKeys := make([]string, len(aliasKeys))
+			for j, i := range aliasIndexes {
+				fieldKeys[j] = definitions[i].key
+			}
+
+			return c.lookupError(err, fieldKeys)
+		}
+
+		for j, value := range aliasValues {
+			i := aliasIndexes[j]
+
+			if value.found && !sourceValues[i].found {
+				if err := c.useAlias(report, definitions[i], "configuration key", aliasKeys[j], definitions[i].key); err != nil {
+					return err
+				}
+
+				sourceValues[i] = value
+			}
+		}
+	}
+
+	// Values resolved by custom tags take precedence over sources
+	resolvedByTags := make([]bool, len(definitions))
+
+	for i, def := range definitions {
+		value, ok, err := resolveCustomTags(ctx, def)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			sourceValues[i] = sourceValue{
+				value: value,
+				found: true,
+			}
+			resolvedByTags[i] = true
+		}
+	}
+
+	// Flags and environment variables bound to the fields (used for reporting the origin of the values)
+	boundFlags := make([]*pflag.Flag, len(definitions))
+	boundEnvs := make([]string, len(definitions))
+
+	// Flags of the former keys sharing the value of the field flags
+	aliasFlags := make([][]*pflag.Flag, len(definitions))
+
+	// Load definitions into Viper
+	for i, def := range definitions {
+		// Set value override
+		if def.hasOverride && c.prefillMode == PrefillOverride {
+			c.viper.Set(def.key, def.overrideValue)
+		}
+
+		// Map flag to field
+		if def.hasFlag {
+			parseFlags = true
+
+			flag, err := c.defineFlag(flags, def)
+			if err != nil {
+				return err
+			}
+
+			flag.Hidden = def.hidden
+
+			// Expose the allowed values to shell completion generators
+			if len(def.choices) > 0 {
+				flags.SetAnnotation(flag.Name, FlagAnnotationChoices, def.choices)
+			}
+
+			c.viper.BindPFlag(def.key, flag)
+			boundFlags[i] = flag
+
+			aliasFlags[i] = c.defineAliasFlags(flags, flag, def)
+		}
+
+		// Map environment variable to field
+		if def.hasEnv {
+			envName, legacy := c.lookupEnvName(def.envAlias, def.envAliases...)
+			if legacy {
+				c.warn(report, "environment variable %s is deprecated, use %s instead", envName, c.mergeWithEnvPrefix(def.envAlias))
+			}
+
+			// Fall back to the environment variables of the former keys
+			if _, ok := c.lookupEnv(envName); !ok {
+				if aliasName, ok := c.lookupAliasEnv(def); ok {
+					if err := c.useAlias(report, def, "environment variable", aliasName, envName); err != nil {
+						return err
+					}
+
+					envName = aliasName
+				}
+			}
+
+			boundEnvs[i] = envName
+		}
+
+		// Source values are registered as defaults, so that everything else can override them
+		if sourceValues[i].found {
+			c.viper.SetDefault(def.key, sourceValues[i].value)
+		} else if def.hasOverride && c.prefillMode == PrefillMerge { // Keep pre-populated value unless it's resolved from somewhere else
+			c.viper.SetDefault(def.key, def.overrideValue)
+		} else if def.hasDefault { // Set default (if any)
+			c.viper.SetDefault(def.key, def.defaultValue)
+		}
+	}
+
+	// Only parse flags if there is any
+	if parseFlags {
+		args := c.args
+		var rest []string
+
+		if c.slashFlags {
+			args = convertSlashFlags(flags, args)
+		}
+
+		// Collect unknown flags instead of passing them to the flag set
+		if c.allowUnknownFlags {
+			args, rest = splitUnknownFlags(flags, args)
+		}
+
+		err := flags.Parse(args)
+		if err == pflag.ErrHelp {
+			return ErrFlagHelp
+		} else if err != nil {
+			return err
+		}
+
+		// The first argument is the program name
+		if !c.allowUnknownFlags && flags.NArg() > 0 {
+			rest = flags.Args()[1:]
+		}
+
+		c.restArgs = rest
+
+		// Flags of the former keys set the value of the field flag
+		for i, flag := range boundFlags {
+			for _, aliasFlag := range aliasFlags[i] {
+				if aliasFlag.Changed && !flag.Changed {
+					if err := c.useAlias(report, definitions[i], "flag", "--"+aliasFlag.Name, "--"+flag.Name); err != nil {
+						return err
+					}
+
+					flag.Changed = true
+				}
+			}
+		}
+	} else if len(c.args) > 0 {
+		c.restArgs = c.args[1:]
+	}
+
+	for i, def := range definitions {
+		origin, name := c.getOrigin(def, boundFlags[i], boundEnvs[i], sourceValues[i])
+
+		report.Fields = append(report.Fields, FieldReport{
+			Key:    def.key,
+			Origin: origin,
+			Name:   name,
+			Secret: def.secret,
+			Metric: def.metric,
+		})
+	}
+
+	// Values of the sources with higher priority than flags or environment variables
+	priorityValues := make([]sourceValue, len(definitions))
+
+	for j, i := range keyIndexes {
+		origin := report.Fields[i].Origin
+
+		switch {
+		case aboveFlagValues[j].found && origin != OriginOverride:
+			priorityValues[i] = aboveFlagValues[j]
+
+		case aboveEnvValues[j].found && origin != OriginOverride && origin != OriginFlag:
+			priorityValues[i] = aboveEnvValues[j]
+
+		default:
+			continue
+		}
+
+		report.Fields[i].Origin = OriginSource
+		report.Fields[i].Name = ""
+	}
+
+	if c.strictConflicts {
+		for i, def := range definitions {
+			if report.Fields[i].Origin != OriginFlag || boundEnvs[i] == "" {
+				continue
+			}
+
+			envValue := c.getenv(boundEnvs[i])
+			if envValue != "" && !isSameValue(def, boundFlags[i].Value.String(), envValue) {
+				return errors.New(c.translate(MsgConflictingValues, def.key, "--"+boundFlags[i].Name, boundEnvs[i]))
+			}
+		}
+	}
+
+	// Template values are expanded once every other field is loaded
+	var templates []fieldDefinition
+	var templateValues []string
+
+	// Apply configuration values
+	for i, def := range definitions {
+		// Slices of structs are loaded from indexed environment variables
+		if def.indexed {
+			name, err := c.applyIndexed(ctx, report, def, boundEnvs[i])
+			if err != nil {
+				return err
+			}
+
+			if name != "" {
+				report.Fields[i].Origin = OriginEnv
+				report.Fields[i].Name = name
+			} else if def.required && report.Fields[i].Origin != OriginOverride {
+				return errors.New(c.translate(MsgRequiredMissing, def.key))
+			}
+
+			continue
+		}
+
+		// Lazy fields not set explicitly are looked up on first use
+		if def.field.Type() == lazyType {
+			switch report.Fields[i].Origin {
+			case OriginOverride, OriginFlag, OriginEnv:
+
+			default:
+				c.bindLazy(def)
+
+				continue
+			}
+		}
+
+		// Elements of lists are loaded into slice fields one by one, so that they may contain the separator
+		source := sourceValues[i]
+		if priorityValues[i].found {
+			source = priorityValues[i]
+		}
+
+		if elements, ok := source.structured.([]string); ok && (priorityValues[i].found || report.Fields[i].Origin == OriginSource) && isElementsField(def) {
+			if err := c.applyElements(ctx, def, elements); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		var value interface{}
+
+		if priorityValues[i].found {
+			value = structuredValue(def, priorityValues[i])
+		} else if report.Fields[i].Origin == OriginSource && sourceValues[i].structured != nil {
+			value = structuredValue(def, sourceValues[i])
+		} else if report.Fields[i].Origin == OriginEnv {
+			// Environment variables are read from the snapshot
+			envValue := c.getenv(boundEnvs[i])
+			report.addEnv(boundEnvs[i], envValue, def.secret)
+
+			value = envValue
+		} else {
+			// Check if value is present in Viper
+			if c.viper.IsSet(def.key) == false {
+				// Check for required value
+				if def.required {
+					// Missing values shouldn't get in the way during development
+					if c.devMode {
+						c.warn(report, "%s", c.translate(MsgRequiredMissing, def.key))
+
+						continue
+					}
+
+					return errors.New(c.translate(MsgRequiredMissing, def.key))
+				}
+
+				// Ignore unset value
+				continue
+			}
+
+			// Get the value from Viper
+			value = c.viper.Get(def.key)
+		}
+
+		if value != nil {
+			// Strings and the values of fields with constraints (allowed values, templates or field hooks)
+			// are processed as strings, so that the constraints apply to coerced values as well
+			if _, isString := value.(string); isString || !c.canAssignValue(def) {
+				prepared, err := c.prepareValue(def, formatValue(value))
+				if err != nil {
+					return err
+				}
+
+				value = prepared
+			}
+
+			if def.template {
+				templates = append(templates, def)
+				templateValues = append(templateValues, value.(string))
+
+				continue
+			}
+
+			// Let the application build the value
+			coerced, err := c.coerceValue(def, value)
+			if err != nil {
+				return err
+			}
+
+			if coerced {
+				continue
+			}
+
+			// Assign typed values (eg. overrides) directly to avoid losing precision
+			if assignValue(def.field, value) {
+				continue
+			}
+
+			// Format the value as string
+			str, ok := value.(string)
+			if !ok {
+				str, err = c.prepareValue(def, formatValue(value))
+				if err != nil {
+					return err
+				}
+			}
+
+			err = c.applyValue(ctx, def, str)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Expand templates in declaration order, so that templates can reference previous ones
+	for i, def := range templates {
+		value, err := expandTemplate(templateValues[i], elem.Interface())
+		if err != nil {
+			return errors.New(c.translate(MsgTemplateFailed, def.key, err))
+		}
+
+		coerced, err := c.coerceValue(def, value)
+		if err != nil {
+			return err
+		}
+
+		if coerced {
+			continue
+		}
+
+		err = c.applyValue(ctx, def, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Pass the loaded values to the setters of the fields
+	for i, def := range definitions {
+		if def.setter == nil || report.Fields[i].Origin == OriginNone {
+			continue
+		}
+
+		if err := def.setter(); err != nil {
+			return fmt.Errorf("failed to set %s: %v", def.key, err)
+		}
+	}
+
+	// Let secrets loaded from the sources re-fetch their value when it expires
+	// (values set by overrides, flags, environment variables or tags are not replaced by the sources)
+	for i, def := range definitions {
+		if def.field.Type() != secretType {
+			continue
+		}
+
+		ttl, err := c.getSecretTTL(def)
+		if err != nil {
+			return err
+		}
+
+		if report.Fields[i].Origin != OriginSource || resolvedByTags[i] {
+			def.field.Addr().Interface().(*Secret).bind(0, nil)
+
+			continue
+		}
+
+		c.bindSecret(def, ttl)
+	}
+
+	// Assemble derived values
+	if err := derive(elem); err != nil {
+		return err
+	}
+
+	for i, def := range definitions {
+		report.Fields[i].Value = formatReportValue(def)
+
+		if def.secret {
+			report.secretKeys = append(report.secretKeys, def.key)
+			report.secretValues = append(report.secretValues, secretValue(def))
+		}
+
+		info := reflect.ValueOf(SourceInfo{
+			Origin: report.Fields[i].Origin,
+			Name:   report.Fields[i].Name,
+		})
+
+		for _, field := range def.sourceInfos {
+			if field.CanSet() {
+				field.Set(info)
+			}
+		}
+	}
+
+	return nil
+}
+
+// lookupError converts an interrupted source lookup to a *LoadTimeoutError if the time limit of Load is exceeded.
+// The keys are the keys of the fields being looked up.
+func (c *Configurator) lookupError(err error, keys []string) error {
+	e, ok := err.(*lookupInterruptedError)
+	if !ok {
+		return err
+	}
+
+	if c.loadTimeout == 0 || e.err != context.DeadlineExceeded {
+		return e.err
+	}
+
+	timeoutErr := &LoadTimeoutError{Timeout: c.loadTimeout}
+
+	seen := make(map[string]bool, len(e.pending))
+	for _, i := range e.pending {
+		if !seen[keys[i]] {
+			seen[keys[i]] = true
+			timeoutErr.Unresolved = append(timeoutErr.Unresolved, keys[i])
+		}
 	}
+
+	return timeoutErr
 }
 
-type Configurator struct {
-	// Used when displaying help
-	name string
+// getOrigin returns where the value of a field comes from (following the precedence rules of Load)
+// along with the name of the flag or environment variable.
+func (c *Configurator) getOrigin(def fieldDefinition, flag *pflag.Flag, envName string, source sourceValue) (Origin, string) {
+	switch {
+	case def.hasOverride && c.prefillMode == PrefillOverride:
+		return OriginOverride, ""
 
-	// Command line arguments (defaults to os.Args)
-	args []string
+	case flag != nil && flag.Changed:
+		return OriginFlag, "--" + flag.Name
 
-	// Environment prefix
-	envPrefix string
+	case envName != "" && c.getenv(envName) != "":
+		return OriginEnv, envName
 
-	viper  *viper.Viper
-	output io.Writer
+	case source.found:
+		return OriginSource, ""
 
-	mu sync.Mutex
+	case def.hasOverride && c.prefillMode == PrefillMerge:
+		return OriginPrefill, ""
+
+	case def.hasDefault:
+		return OriginDefault, ""
+	}
+
+	return OriginNone, ""
 }
 
-// SetEnvPrefix manually sets the environment variable prefix.
-func (c *Configurator) SetEnvPrefix(prefix string) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// applyValue processes a raw value and sets it on the field.
+func (c *Configurator) applyValue(ctx context.Context, def fieldDefinition, value string) error {
+	// If the value is empty string, fall back to the zero value of the type
+	if value == "" {
+		value = formatValue(reflect.Zero(def.field.Type()).Interface())
+	}
 
-	c.envPrefix = prefix
-	c.viper.SetEnvPrefix(prefix)
+	// Limit the time custom decoders can spend on a single field
+	if c.decodeTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, c.decodeTimeout)
+		defer cancel()
+	}
+
+	// Process the value as string
+	return processSeparatedField(ctx, def.field, value, def.separator)
 }
 
-// SetName sets the application name for displaying help.
-func (c *Configurator) SetName(name string) {
+// Usage writes the usage of a configuration struct (flags and environment variables) to a writer.
+// It can be used for displaying help without passing --help to Load (eg. in a custom help command).
+func (c *Configurator) Usage(config interface{}, w io.Writer) error {
+	elem, err := getTarget(config)
+	if err != nil {
+		return err
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.name = name
+	if c.name == "" {
+		c.name = c.args[0]
+	}
+
+	definitions, err := c.getDefinitions(elem)
+	if err != nil {
+		return err
+	}
+
+	c.writeUsage(w, definitions)
+
+	return nil
 }
 
-// SetArgs sets the command line arguments.
-func (c *Configurator) SetArgs(args []string) {
+// writeUsage writes the usage text to a writer.
+func (c *Configurator) writeUsage(w io.Writer, definitions []fieldDefinition) {
+	opts := usageOptions{
+		translate: c.translate,
+		color:     useColor(c.colorMode, w),
+		order:     c.usageOrder,
+		envName:   c.mergeWithEnvPrefix,
+		flagName:  c.mergeWithFlagPrefix,
+	}
+
+	if c.shortEnvUsage {
+		opts.envName = strings.ToUpper
+	}
+
+	fmt.Fprintln(w, c.translate(MsgUsage, c.name))
+	fmt.Fprint(w, getUsage(definitions, opts))
+}
+
+// isChoice checks whether a value is one of the allowed choices.
+func isChoice(choices []string, value string) bool {
+	for _, choice := range choices {
+		if choice == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Flags returns the flag set used for parsing the arguments.
+//
+// Applications can register flags of their own or mark flags hidden or deprecated.
+// The set is populated with the configuration flags by Load.
+func (c *Configurator) Flags() *pflag.FlagSet {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.args = args
+	return c.getFlags()
 }
 
-// SetOutput sets the output writer used for help text and error messages.
-func (c *Configurator) SetOutput(output io.Writer) {
-	c.output = output
-}
+// getFlags returns the flag set, creating it if necessary.
+func (c *Configurator) getFlags() *pflag.FlagSet {
+	if c.flags == nil {
+		name := c.name
+		if name == "" && len(c.args) > 0 {
+			name = c.args[0]
+		}
 
-// out returns the configured output or the default which is STDERR.
-func (c *Configurator) out() io.Writer {
-	if c.output == nil {
-		c.output = os.Stderr
+		c.flags = pflag.NewFlagSet(name, c.flagErrorHandling)
 	}
 
-	return c.output
+	return c.flags
 }
 
-// mergeWithEnvPrefix merges an environment variable alias with the configured prefix.
-// The code bellow is from Viper's source.
-func (c *Configurator) mergeWithEnvPrefix(in string) string {
-	if c.envPrefix != "" {
-		return strings.ToUpper(c.envPrefix + "_" + in)
+// getDefinitions collects the field definitions of a struct according to the configured options.
+func (c *Configurator) getDefinitions(elem reflect.Value) ([]fieldDefinition, error) {
+	if c.strictTags {
+		if err := lintTags(elem.Type(), c.allowedTags); err != nil {
+			return nil, err
+		}
 	}
 
-	return strings.ToUpper(in)
+	return getDefinitionsWithOptions(elem, definitionOptions{
+		limits:      c.limits,
+		splitWords:  c.splitWords,
+		snakeEnv:    c.defaultCase&SnakeEnv != 0,
+		spinalFlags: c.defaultCase&SpinalFlags != 0,
+
+		keyDelimiter:  c.keyDelimiter,
+		mergeEmbedded: c.mergeEmbedded,
+
+		envconfig: c.envconfigCompat,
+		trimSpace: c.trimSpace,
+		mode:      c.mode,
+	})
 }
 
-func (c *Configurator) Load(config interface{}) error {
-	// Initial checks to see whether the config can be used as a target
+// getTarget checks whether a value can be used as a configuration target and returns the struct it points to.
+func getTarget(config interface{}) (reflect.Value, error) {
 	ptr := reflect.ValueOf(config)
 
 	if ptr.Kind() != reflect.Ptr {
-		return ErrNotStructPointer
+		return reflect.Value{}, ErrNotStructPointer
 	}
 
 	elem := ptr.Elem()
 
 	if elem.Kind() != reflect.Struct {
-		return ErrNotStruct
+		return reflect.Value{}, ErrNotStruct
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	return elem, nil
+}
 
-	if c.name == "" {
-		c.name = c.args[0]
+// structuredValue returns the value of a source formatted for a field:
+// the entries of objects are joined with the separator of map fields (other fields receive the value as is).
+func structuredValue(def fieldDefinition, value sourceValue) string {
+	if _, ok := value.structured.(map[string]string); !ok || def.field.Kind() != reflect.Map || canDecode(def.field) {
+		return value.value
 	}
 
-	flags := pflag.NewFlagSet(c.name, pflag.ContinueOnError)
-	flags.SetOutput(c.out())
+	return formatSeparatedValue(value.structured, def.separator)
+}
 
-	var parseFlags bool
+// isElementsField checks whether the elements of a list can be loaded into a field one by one
+// (slice fields, except for byte slices, slices decoding themselves and templates).
+func isElementsField(def fieldDefinition) bool {
+	return def.field.Kind() == reflect.Slice &&
+		def.field.Type().Elem().Kind() != reflect.Uint8 &&
+		!canDecode(def.field) &&
+		!def.template
+}
 
-	definitions := getDefinitions(elem)
+// applyElements processes the elements of a list and sets them on a slice field.
+// Every element is processed separately (including the field hooks and the choices check).
+func (c *Configurator) applyElements(ctx context.Context, def fieldDefinition, elements []string) error {
+	elementDef := def
+	elementDef.separator = ""
 
-	flags.Usage = func() {
-		usage := getUsage(definitions)
-		fmt.Fprintf(c.out(), "Usage of %s:\n", c.name)
-		fmt.Fprint(c.out(), usage)
-	}
+	slice := reflect.MakeSlice(def.field.Type(), len(elements), len(elements))
 
-	// Load definitions into Viper
-	for _, def := range definitions {
-		// Set value override
-		if def.hasOverride {
-			c.viper.Set(def.key, def.overrideValue)
+	for i, element := range elements {
+		value, err := c.prepareValue(elementDef, element)
+		if err != nil {
+			return err
 		}
 
-		// Map flag to field
-		if def.hasFlag {
-			parseFlags = true
+		if err := processField(ctx, slice.Index(i), value); err != nil {
+			return err
+		}
+	}
 
-			// Bool flags can be supplied without a value
-			if def.field.Kind() == reflect.Bool {
-				flags.Bool(def.flagAlias, false, def.usage)
-			} else {
-				flags.String(def.flagAlias, "", def.usage)
-			}
+	def.field.Set(slice)
 
-			flag := flags.Lookup(def.flagAlias)
+	return nil
+}
 
-			c.viper.BindPFlag(def.key, flag)
-		}
+// getSecretTTL returns the TTL of a secret field (zero if it has none).
+func (c *Configurator) getSecretTTL(def fieldDefinition) (time.Duration, error) {
+	if def.ttl == "" {
+		return 0, nil
+	}
 
-		// Map environment variable to field
-		if def.hasEnv {
-			c.viper.BindEnv(def.key, c.mergeWithEnvPrefix(def.envAlias))
-		}
+	ttl, err := time.ParseDuration(def.ttl)
+	if err != nil {
+		return 0, errors.New(c.translate(MsgInvalidTTL, def.key, err))
+	}
 
-		// Set default (if any)
-		if def.hasDefault {
-			c.viper.SetDefault(def.key, def.defaultValue)
+	return ttl, nil
+}
+
+// bindSecret configures a secret field to re-fetch it's value from the sources when it expires.
+func (c *Configurator) bindSecret(def fieldDefinition, ttl time.Duration) {
+	sources := c.sourcesByPriority(c.getSources())
+	key := def.key
+
+	def.field.Addr().Interface().(*Secret).bind(ttl, func() (string, bool, error) {
+		value, ok, err := lookupSources(context.Background(), sources, key)
+		flushSources(sources)
+
+		if err != nil || !ok {
+			return "", ok, err
 		}
+
+		// Re-fetched values are processed the same way as loaded ones
+		value, err = c.prepareValue(def, value)
+
+		return value, err == nil, err
+	})
+}
+
+// coerceValue lets the coercer (if any) build the value of a field.
+// It reports whether the value was set by the coercer.
+func (c *Configurator) coerceValue(def fieldDefinition, value interface{}) (bool, error) {
+	if c.coercer == nil {
+		return false, nil
 	}
 
-	// Only parse flags if there is any
-	if parseFlags {
-		err := flags.Parse(c.args)
-		if err == pflag.ErrHelp {
-			return ErrFlagHelp
-		} else if err != nil {
-			return err
-		}
+	coerced, ok := c.coercer(def.field.Type(), value)
+	if !ok {
+		return false, nil
 	}
 
-	// Apply configuration values
-	for _, def := range definitions {
-		// Check if value is present in Viper
-		if c.viper.IsSet(def.key) == false {
-			// Check for required value
-			if def.required {
-				return fmt.Errorf("required field %s missing value", def.key)
-			}
+	if err := setCoercedValue(def.field, coerced); err != nil {
+		return false, fmt.Errorf("failed to coerce %s: %v", def.key, err)
+	}
 
-			// Ignore unset value
-			continue
-		}
+	return true, nil
+}
 
-		// Get the value from Viper
-		value := c.viper.Get(def.key)
+// canAssignValue checks whether typed values can be assigned to a field directly.
+// Fields with allowed values, templates or field hooks need the value as string, so that the constraints are not bypassed.
+func (c *Configurator) canAssignValue(def fieldDefinition) bool {
+	return len(def.choices) == 0 && !def.template && len(c.fieldHooks) == 0
+}
 
-		if value != nil {
-			// Format the value as string
-			value := fmt.Sprintf("%v", value)
+// prepareValue processes a raw value before it's set on a field:
+// it cleans and decrypts the value, applies the field hooks and checks it against the allowed values.
+func (c *Configurator) prepareValue(def fieldDefinition, value string) (string, error) {
+	value = def.cleanValue(value)
 
-			// If the value is empty string, fall back to the zero value of the type
-			if value == "" {
-				value = fmt.Sprintf("%v", reflect.Zero(def.field.Type()).Interface())
-			}
+	// Decrypt the value if it is encrypted
+	value, err := decryptValue(c.decryptors, value)
+	if err != nil {
+		return "", errors.New(c.translate(MsgDecryptFailed, def.key, err))
+	}
 
-			// Process the value as string
-			err := processField(def.field, value)
+	// Transform the value
+	value, err = c.applyFieldHooks(def, value)
+	if err != nil {
+		return "", err
+	}
 
-			if err != nil {
-				return err
+	// Every element of slice values has to be one of the choices
+	if len(def.choices) > 0 {
+		elements := []string{value}
+		if def.separator != "" {
+			elements = splitSeparatedValue(value, def.separator)
+		}
+
+		for _, element := range elements {
+			if !isChoice(def.choices, element) {
+				return "", errors.New(c.translate(MsgInvalidChoice, element, def.key, strings.Join(def.choices, ", ")))
 			}
 		}
 	}
 
-	return nil
+	return value, nil
 }
 
-// getUsage returns the usage string for flags and environment variables.
-func getUsage(definitions []fieldDefinition) string {
-	buf := new(bytes.Buffer)
+// usageOptions controls the formatting of the usage string.
+type usageOptions struct {
+	translate Translator
+	color     bool
+	order     UsageOrder
 
-	var flagLines []string
-	var envLines []string
+	// envName returns the environment variable name displayed for an alias
+	envName func(alias string) string
+
+	// flagName returns the flag name displayed for an alias
+	flagName func(alias string) string
+}
+
+// getUsage returns the usage string for flags and environment variables.
+func getUsage(definitions []fieldDefinition, opts usageOptions) string {
+	flagLines := make([]string, 0, len(definitions))
+	envLines := make([]string, 0, len(definitions))
 
 	flagMaxlen := 0
 	envMaxlen := 0
 
-	for _, definition := range definitions {
-		// Default value hint
-		def := ""
-		if definition.hasDefault {
-			if definition.field.Type().Name() == "string" {
-				def += fmt.Sprintf(" (default %q)", definition.defaultValue)
-			} else {
-				def += fmt.Sprintf(" (default %s)", definition.defaultValue)
-			}
+	var section string
+
+	flagDefinitions := sortUsageDefinitions(definitions, opts.order, func(def fieldDefinition) string { return def.flagAlias })
+	for _, definition := range flagDefinitions {
+		if !definition.hasFlag || definition.hidden {
+			continue
 		}
 
-		if definition.hasFlag {
-			line := ""
+		if definition.section != section {
+			section = definition.section
+			flagLines = append(flagLines, getUsageSectionTitle(section))
+		}
 
-			line = fmt.Sprintf("      --%s", definition.flagAlias)
+		line := "      " + colorize("--"+opts.flagName(definition.flagAlias), colorName, opts.color)
+
+		// Make an educated guess about the flag
+		// TODO: check pflag UnquoteUsage
+		name := definition.field.Type().Name()
+		switch name {
+		case "bool":
+			name = ""
+		case "float64":
+			name = "float"
+		case "int64":
+			name = "int"
+		case "uint64":
+			name = "uint"
+		}
 
-			// Make an educated guess about the flag
-			// TODO: check pflag UnquoteUsage
-			name := definition.field.Type().Name()
-			switch name {
-			case "bool":
-				name = ""
-			case "float64":
-				name = "float"
-			case "int64":
-				name = "int"
-			case "uint64":
-				name = "uint"
-			}
+		if name != "" {
+			line += " " + name
+		}
 
-			if name != "" {
-				line += " " + name
-			}
+		// This special character will be replaced with spacing once the
+		// correct alignment is calculated
+		line += "\x00"
+		if len(line) > flagMaxlen {
+			flagMaxlen = len(line)
+		}
 
-			// This special character will be replaced with spacing once the
-			// correct alignment is calculated
-			line += "\x00"
-			if len(line) > flagMaxlen {
-				flagMaxlen = len(line)
-			}
+		line += definition.usage
+		line += getUsageHint(definition, opts)
+
+		flagLines = append(flagLines, line)
+	}
 
-			line += definition.usage
-			line += def
+	section = ""
 
-			flagLines = append(flagLines, line)
+	envDefinitions := sortUsageDefinitions(definitions, opts.order, func(def fieldDefinition) string { return def.envAlias })
+	for _, definition := range envDefinitions {
+		if !definition.hasEnv || definition.hidden {
+			continue
 		}
 
-		if definition.hasEnv {
-			line := ""
+		if definition.section != section {
+			section = definition.section
+			envLines = append(envLines, getUsageSectionTitle(section))
+		}
 
-			line = fmt.Sprintf("      %s", c.mergeWithEnvPrefix(definition.envAlias))
+		line := "      " + colorize(opts.envName(definition.envAlias), colorName, opts.color)
 
-			name := definition.field.Type().Name()
-			switch name {
-			case "float64":
-				name = "float"
-			case "int64":
-				name = "int"
-			case "uint64":
-				name = "uint"
-			}
+		name := definition.field.Type().Name()
+		switch name {
+		case "float64":
+			name = "float"
+		case "int64":
+			name = "int"
+		case "uint64":
+			name = "uint"
+		}
 
-			if name != "" {
-				line += " " + name
-			}
+		if name != "" {
+			line += " " + name
+		}
 
-			// This special character will be replaced with spacing once the
-			// correct alignment is calculated
-			line += "\x00"
-			if len(line) > envMaxlen {
-				envMaxlen = len(line)
-			}
+		// This special character will be replaced with spacing once the
+		// correct alignment is calculated
+		line += "\x00"
+		if len(line) > envMaxlen {
+			envMaxlen = len(line)
+		}
 
-			line += definition.usage
-			line += def
+		line += definition.usage
+		line += getUsageHint(definition, opts)
 
-			envLines = append(envLines, line)
-		}
+		envLines = append(envLines, line)
 	}
 
+	var buf bytes.Buffer
+
 	if len(flagLines) > 0 {
-		fmt.Fprintln(buf, "\n\nFLAGS:\n")
+		buf.WriteString("\n\n" + opts.translate(MsgFlagsTitle) + ":\n\n")
+		writeUsageLines(&buf, flagLines, flagMaxlen)
+	}
+
+	if len(envLines) > 0 {
+		buf.WriteString("\n\n" + opts.translate(MsgEnvTitle) + ":\n\n")
+		writeUsageLines(&buf, envLines, envMaxlen)
+	}
+
+	return buf.String()
+}
+
+// getUsageHint returns the hints of a field (unit, format, default value or required marker and example).
+func getUsageHint(definition fieldDefinition, opts usageOptions) string {
+	var hint string
+
+	if definition.unit != "" {
+		hint += " " + opts.translate(MsgUnit, definition.unit)
+	}
+
+	if definition.format != "" {
+		hint += " " + opts.translate(MsgFormat, definition.format)
+	}
+
+	hint += getValueHint(definition, opts)
+
+	if definition.example != "" {
+		hint += " " + colorize(opts.translate(MsgExample, definition.example), colorDefault, opts.color)
+	}
+
+	return hint
+}
+
+// getValueHint returns the default value or the required marker of a field.
+func getValueHint(definition fieldDefinition, opts usageOptions) string {
+	if definition.hasDefault {
+		// Never reveal default values of sensitive fields
+		if definition.secret {
+			return " " + colorize(opts.translate(MsgDefault, hiddenValue), colorDefault, opts.color)
+		}
 
-		for _, line := range flagLines {
-			sidx := strings.Index(line, "\x00")
-			spacing := strings.Repeat(" ", flagMaxlen-sidx)
-			// maxlen + 2 comes from + 1 for the \x00 and + 1 for the (deliberate) off-by-one in maxlen-sidx
-			fmt.Fprintln(buf, line[:sidx], spacing, line[sidx+1:])
+		if definition.field.Type().Name() == "string" {
+			return " " + colorize(opts.translate(MsgDefault, strconv.Quote(definition.defaultValue)), colorDefault, opts.color)
 		}
+
+		return " " + colorize(opts.translate(MsgDefault, definition.defaultValue), colorDefault, opts.color)
+	} else if definition.required {
+		return " " + colorize(opts.translate(MsgRequired), colorRequired, opts.color)
 	}
 
-	if len(envLines) > 0 {
-		fmt.Fprintln(buf, "\n\nENVIRONMENT VARIABLES:\n")
+	return ""
+}
+
+// getUsageSectionTitle returns the title line of a help section.
+func getUsageSectionTitle(section string) string {
+	return "\n    " + section + ":\n"
+}
 
-		for _, line := range envLines {
-			sidx := strings.Index(line, "\x00")
-			spacing := strings.Repeat(" ", envMaxlen-sidx)
-			// maxlen + 2 comes from + 1 for the \x00 and + 1 for the (deliberate) off-by-one in maxlen-sidx
-			fmt.Fprintln(buf, line[:sidx], spacing, line[sidx+1:])
+// sortUsageDefinitions returns a sorted copy of the definitions for displaying them in the usage.
+//
+// Definitions are grouped by section: definitions without a section come first,
+// sections follow in the order they are declared in.
+// Within a section definitions with a higher weight come first, the rest are sorted according to the usage order.
+func sortUsageDefinitions(definitions []fieldDefinition, order UsageOrder, name func(fieldDefinition) string) []fieldDefinition {
+	sorted := make([]fieldDefinition, len(definitions))
+	copy(sorted, definitions)
+
+	sections := map[string]int{"": 0}
+	for _, def := range definitions {
+		if _, ok := sections[def.section]; !ok {
+			sections[def.section] = len(sections)
 		}
 	}
 
-	return buf.String()
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].section != sorted[j].section {
+			return sections[sorted[i].section] < sections[sorted[j].section]
+		}
+
+		if sorted[i].weight != sorted[j].weight {
+			return sorted[i].weight > sorted[j].weight
+		}
+
+		if order == UsageOrderAlphabetical {
+			return name(sorted[i]) < name(sorted[j])
+		}
+
+		return false
+	})
+
+	return sorted
+}
+
+// writeUsageLines writes usage lines to a buffer replacing the \x00 character with spacing.
+func writeUsageLines(buf *bytes.Buffer, lines []string, maxlen int) {
+	for i, line := range lines {
+		sidx := strings.Index(line, "\x00")
+
+		// Section titles are not aligned (nor separated from the title of the list)
+		if sidx < 0 {
+			if i == 0 {
+				line = strings.TrimPrefix(line, "\n")
+			}
+
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+
+			continue
+		}
+
+		// maxlen + 2 comes from + 1 for the \x00 and + 1 for the (deliberate) off-by-one in maxlen-sidx
+		buf.WriteString(line[:sidx])
+		buf.WriteByte(' ')
+		buf.WriteString(strings.Repeat(" ", maxlen-sidx))
+		buf.WriteByte(' ')
+		buf.WriteString(line[sidx+1:])
+		buf.WriteByte('\n')
+	}
 }
 
-func processField(field reflect.Value, value string) error {
+// processField parses a string value and sets it on the field.
+//
+// Numbers are accepted in decimal, hexadecimal ("0x1F"), octal ("017") and scientific ("1e6") notation
+// with digits optionally separated by underscores ("1_000_000").
+func processField(ctx context.Context, field reflect.Value, value string) error {
 	if canDecode(field) {
-		return decode(field, value)
+		return decode(ctx, field, value)
 	}
 
 	typ := field.Type()
@@ -352,7 +2177,7 @@ func processField(field reflect.Value, value string) error {
 			d, err = time.ParseDuration(value)
 			val = int64(d)
 		} else {
-			val, err = strconv.ParseInt(value, 0, typ.Bits())
+			val, err = parseInt(value, typ.Bits())
 		}
 
 		if err != nil {
@@ -362,7 +2187,7 @@ func processField(field reflect.Value, value string) error {
 		field.SetInt(val)
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint32, reflect.Uint64:
-		val, err := strconv.ParseUint(value, 0, typ.Bits())
+		val, err := parseUint(value, typ.Bits())
 		if err != nil {
 			return err
 		}
@@ -370,7 +2195,7 @@ func processField(field reflect.Value, value string) error {
 		field.SetUint(val)
 
 	case reflect.Float32, reflect.Float64:
-		val, err := strconv.ParseFloat(value, typ.Bits())
+		val, err := parseFloat(value, typ.Bits())
 		if err != nil {
 			return err
 		}
@@ -378,13 +2203,88 @@ func processField(field reflect.Value, value string) error {
 		field.SetFloat(val)
 
 	case reflect.Bool:
-		val, err := strconv.ParseBool(value)
+		val, err := parseBool(value)
 		if err != nil {
 			return err
 		}
 
 		field.SetBool(val)
+
+	case reflect.Slice, reflect.Map:
+		return processSeparatedField(ctx, field, value, defaultSeparator)
+	}
+
+	return nil
+}
+
+// processSeparatedField parses a string value and sets it on the field
+// splitting it into elements by the separator if the field is a slice (eg. "a,b,c") or a map (eg. "a=1,b=2").
+// Surrounding whitespace is trimmed from the elements, byte slices are set from the value as is.
+func processSeparatedField(ctx context.Context, field reflect.Value, value string, separator string) error {
+	if field.Kind() == reflect.Map && !canDecode(field) {
+		return processMapField(ctx, field, value, separator)
+	}
+
+	if field.Kind() != reflect.Slice || canDecode(field) {
+		return processField(ctx, field, value)
+	}
+
+	if field.Type().Elem().Kind() == reflect.Uint8 && !canDecode(reflect.New(field.Type().Elem()).Elem()) {
+		field.SetBytes([]byte(value))
+
+		return nil
+	}
+
+	if value == "" {
+		field.Set(reflect.Zero(field.Type()))
+
+		return nil
+	}
+
+	elements := splitSeparatedValue(value, separator)
+	slice := reflect.MakeSlice(field.Type(), len(elements), len(elements))
+
+	for i, element := range elements {
+		if err := processField(ctx, slice.Index(i), element); err != nil {
+			return err
+		}
+	}
+
+	field.Set(slice)
+
+	return nil
+}
+
+// processMapField parses a list of key=value pairs separated by the separator and sets them on a map field.
+// Surrounding whitespace is trimmed from the keys and values, the last value of repeated keys is kept.
+func processMapField(ctx context.Context, field reflect.Value, value string, separator string) error {
+	if value == "" {
+		field.Set(reflect.Zero(field.Type()))
+
+		return nil
+	}
+
+	typ := field.Type()
+	entries := splitSeparatedValue(value, separator)
+	m := reflect.MakeMapWithSize(typ, len(entries))
+
+	for _, entry := range entries {
+		i := strings.Index(entry, "=")
+		if i < 0 {
+			return fmt.Errorf("invalid map entry %q, expected key=value", entry)
+		}
+
+		key := strings.TrimSpace(entry[:i])
+		elem := reflect.New(typ.Elem()).Elem()
+
+		if err := processField(ctx, elem, strings.TrimSpace(entry[i+1:])); err != nil {
+			return fmt.Errorf("invalid value of map key %s: %v", key, err)
+		}
+
+		m.SetMapIndex(reflect.ValueOf(key).Convert(typ.Key()), elem)
 	}
 
+	field.Set(m)
+
 	return nil
 }
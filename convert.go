@@ -0,0 +1,290 @@
+package nest
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Coercer builds a value of the target type from a raw value (eg. a string read from an environment variable).
+// The boolean return value reports whether the coercer handled the type,
+// otherwise the default conversion logic is applied.
+type Coercer func(target reflect.Type, raw interface{}) (interface{}, bool)
+
+// setCoercedValue sets a value returned by a coercer on the field.
+func setCoercedValue(field reflect.Value, value interface{}) error {
+	v := reflect.ValueOf(value)
+
+	if !v.IsValid() {
+		field.Set(reflect.Zero(field.Type()))
+
+		return nil
+	}
+
+	if v.Type().AssignableTo(field.Type()) {
+		field.Set(v)
+
+		return nil
+	}
+
+	if v.Type().ConvertibleTo(field.Type()) && v.Kind() == field.Kind() {
+		field.Set(v.Convert(field.Type()))
+
+		return nil
+	}
+
+	return fmt.Errorf("cannot assign %s to %s", v.Type(), field.Type())
+}
+
+// assignValue assigns a typed value to a field without converting it to string first.
+//
+// Numeric values are converted between integer, unsigned integer and float fields when the conversion is lossless
+// (eg. integral floats from JSON documents to integer fields), otherwise false is returned.
+// String values and types decoding themselves are always processed as strings.
+// Named types (eg. time.Duration) only accept values of the same type, since a plain number is not a valid duration.
+func assignValue(field reflect.Value, value interface{}) bool {
+	v := reflect.ValueOf(value)
+
+	if v.Kind() == reflect.String || canDecode(field) {
+		return false
+	}
+
+	if field.Type().PkgPath() != "" && v.Type() != field.Type() {
+		return false
+	}
+
+	switch field.Kind() {
+	case reflect.Slice, reflect.Map:
+		if !v.Type().AssignableTo(field.Type()) {
+			return false
+		}
+
+		field.Set(v)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := toInt64(v)
+		if !ok || field.OverflowInt(i) {
+			return false
+		}
+
+		field.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, ok := toUint64(v)
+		if !ok || field.OverflowUint(u) {
+			return false
+		}
+
+		field.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, ok := toFloat64(v)
+		if !ok || field.OverflowFloat(f) {
+			return false
+		}
+
+		field.SetFloat(f)
+
+	case reflect.Bool:
+		if v.Kind() != reflect.Bool {
+			return false
+		}
+
+		field.SetBool(v.Bool())
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// toInt64 converts a numeric value to int64 if it's possible without losing information.
+func toInt64(v reflect.Value) (int64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt64 {
+			return 0, false
+		}
+
+		return int64(v.Uint()), true
+
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, false
+		}
+
+		return int64(f), true
+	}
+
+	return 0, false
+}
+
+// toUint64 converts a numeric value to uint64 if it's possible without losing information.
+func toUint64(v reflect.Value) (uint64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return 0, false
+		}
+
+		return uint64(v.Int()), true
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), true
+
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+			return 0, false
+		}
+
+		return uint64(f), true
+	}
+
+	return 0, false
+}
+
+// toFloat64 converts a numeric value to float64 if it's possible without losing information.
+func toFloat64(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f := float64(v.Int())
+		if f >= math.MaxInt64 || int64(f) != v.Int() {
+			return 0, false
+		}
+
+		return f, true
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f := float64(v.Uint())
+		if f >= math.MaxUint64 || uint64(f) != v.Uint() {
+			return 0, false
+		}
+
+		return f, true
+
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+
+	return 0, false
+}
+
+// parseInt parses a signed integer accepting the following notations consistently:
+// decimal ("1000"), digits separated by underscores ("1_000"), hexadecimal ("0x3E8"), octal ("01750")
+// and integral scientific notation ("1e3").
+func parseInt(value string, bits int) (int64, error) {
+	value = removeUnderscores(value)
+
+	i, err := strconv.ParseInt(value, 0, bits)
+	if err == nil || isRangeError(err) {
+		return i, err
+	}
+
+	f, ferr := strconv.ParseFloat(value, 64)
+	if ferr != nil || f != math.Trunc(f) {
+		return 0, err
+	}
+
+	limit := math.Ldexp(1, bits-1)
+	if f < -limit || f >= limit {
+		return 0, &strconv.NumError{Func: "ParseInt", Num: value, Err: strconv.ErrRange}
+	}
+
+	return int64(f), nil
+}
+
+// parseUint parses an unsigned integer accepting the same notations as parseInt.
+func parseUint(value string, bits int) (uint64, error) {
+	value = removeUnderscores(value)
+
+	u, err := strconv.ParseUint(value, 0, bits)
+	if err == nil || isRangeError(err) {
+		return u, err
+	}
+
+	f, ferr := strconv.ParseFloat(value, 64)
+	if ferr != nil || f != math.Trunc(f) || f < 0 {
+		return 0, err
+	}
+
+	if f >= math.Ldexp(1, bits) {
+		return 0, &strconv.NumError{Func: "ParseUint", Num: value, Err: strconv.ErrRange}
+	}
+
+	return uint64(f), nil
+}
+
+// parseFloat parses a floating point number accepting decimal ("0.5"), scientific ("5e-1")
+// and hexadecimal integer ("0x1F") notations with digits optionally separated by underscores ("1_000.5").
+func parseFloat(value string, bits int) (float64, error) {
+	value = removeUnderscores(value)
+
+	f, err := strconv.ParseFloat(value, bits)
+	if err == nil || isRangeError(err) {
+		return f, err
+	}
+
+	// Hexadecimal integers are not accepted by ParseFloat without an exponent
+	if i, ierr := strconv.ParseInt(value, 0, 64); ierr == nil {
+		return float64(i), nil
+	}
+
+	return 0, err
+}
+
+// parseBool parses a boolean value accepting the common spellings case-insensitively
+// besides the ones accepted by strconv.ParseBool: yes/no, y/n and on/off.
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "y", "on":
+		return true, nil
+
+	case "no", "n", "off":
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(strings.ToLower(value))
+	if err != nil {
+		return false, &strconv.NumError{Func: "ParseBool", Num: value, Err: strconv.ErrSyntax}
+	}
+
+	return b, nil
+}
+
+// removeUnderscores removes underscores separating digits (eg. "1_000" becomes "1000").
+// Underscores in any other position are kept, so that parsing fails on them.
+func removeUnderscores(value string) string {
+	if !strings.Contains(value, "_") {
+		return value
+	}
+
+	isDigit := func(b byte) bool {
+		return (b >= '0' && b <= '9') || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+	}
+
+	buf := make([]byte, 0, len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] == '_' && i > 0 && i < len(value)-1 && isDigit(value[i-1]) && isDigit(value[i+1]) {
+			continue
+		}
+
+		buf = append(buf, value[i])
+	}
+
+	return string(buf)
+}
+
+// isRangeError checks whether a number parsing error is caused by a value out of range.
+func isRangeError(err error) bool {
+	numErr, ok := err.(*strconv.NumError)
+
+	return ok && numErr.Err == strconv.ErrRange
+}
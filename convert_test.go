@@ -0,0 +1,167 @@
+package nest
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssignValue(t *testing.T) {
+	tests := map[string]struct {
+		target   interface{}
+		value    interface{}
+		expected interface{}
+		ok       bool
+	}{
+		"float64_precision":   {new(float64), 0.30000000000000004, 0.30000000000000004, true},
+		"float64_from_int":    {new(float64), 1 << 53, float64(1 << 53), true},
+		"float64_from_bigint": {new(float64), int64(1<<53 + 1), float64(0), false},
+		"float32_overflow":    {new(float32), math.MaxFloat64, float32(0), false},
+		"int64_big":           {new(int64), int64(9007199254740993), int64(9007199254740993), true},
+		"int64_from_float":    {new(int64), 1e18, int64(1e18), true},
+		"int64_from_fraction": {new(int64), 1.5, int64(0), false},
+		"int8_overflow":       {new(int8), 128, int8(0), false},
+		"uint64_max":          {new(uint64), uint64(math.MaxUint64), uint64(math.MaxUint64), true},
+		"uint_from_negative":  {new(uint), -1, uint(0), false},
+		"duration":            {new(time.Duration), 10 * time.Second, 10 * time.Second, true},
+		"duration_from_int":   {new(time.Duration), 10, time.Duration(0), false},
+		"bool":                {new(bool), true, true, true},
+		"bool_from_int":       {new(bool), 1, false, false},
+		"string":              {new(string), "string", "", false},
+		"int_from_string":     {new(int), "1", 0, false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			field := reflect.ValueOf(test.target).Elem()
+
+			assert.Equal(t, test.ok, assignValue(field, test.value))
+			assert.Equal(t, test.expected, field.Interface())
+		})
+	}
+}
+
+func TestParseInt(t *testing.T) {
+	tests := map[string]int64{
+		"1000000":   1000000,
+		"-1000000":  -1000000,
+		"1_000_000": 1000000,
+		"1e6":       1000000,
+		"-1.5e3":    -1500,
+		"0x1F":      31,
+		"0x_1F":     31,
+		"017":       15,
+	}
+
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			actual, err := parseInt(input, 64)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestParseInt_Invalid(t *testing.T) {
+	tests := map[string]int{
+		"1.5":    64,
+		"1e-1":   64,
+		"_1":     64,
+		"1__0":   64,
+		"128":    8,
+		"1e3":    8,
+		"9.3e18": 64,
+		"value":  64,
+	}
+
+	for input, bits := range tests {
+		t.Run(input, func(t *testing.T) {
+			_, err := parseInt(input, bits)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseUint(t *testing.T) {
+	tests := map[string]uint64{
+		"1000000":   1000000,
+		"1_000_000": 1000000,
+		"1e6":       1000000,
+		"0x1F":      31,
+	}
+
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			actual, err := parseUint(input, 64)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestParseUint_Invalid(t *testing.T) {
+	tests := map[string]int{
+		"-1":   64,
+		"-1e3": 64,
+		"256":  8,
+		"1e3":  8,
+	}
+
+	for input, bits := range tests {
+		t.Run(input, func(t *testing.T) {
+			_, err := parseUint(input, bits)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseFloat(t *testing.T) {
+	tests := map[string]float64{
+		"0.5":         0.5,
+		"5e-1":        0.5,
+		"1e6":         1000000,
+		"1_000_000.5": 1000000.5,
+		"0x1F":        31,
+	}
+
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			actual, err := parseFloat(input, 64)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestParseBool(t *testing.T) {
+	tests := map[string]bool{
+		"true":  true,
+		"TRUE":  true,
+		"tRue":  true,
+		"1":     true,
+		"yes":   true,
+		"Y":     true,
+		"On":    true,
+		"false": false,
+		"0":     false,
+		"NO":    false,
+		"n":     false,
+		"off":   false,
+	}
+
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			actual, err := parseBool(input)
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, actual)
+		})
+	}
+
+	_, err := parseBool("maybe")
+	assert.EqualError(t, err, `strconv.ParseBool: parsing "maybe": invalid syntax`)
+}
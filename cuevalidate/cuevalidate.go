@@ -0,0 +1,52 @@
+// Package cuevalidate validates configuration documents against a CUE schema.
+//
+// Validation is delegated to the cue command line tool (https://cuelang.org),
+// which has to be available in the PATH:
+//
+//	source := nest.NewFileSource("config.json")
+//	source.SetValidator(cuevalidate.New("schema.cue", "#Config"))
+package cuevalidate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"strings"
+
+	"github.com/goph/nest"
+)
+
+// New returns a validator checking documents against the definition of a CUE schema file (eg. #Config).
+// When definition is empty, documents are unified with the schema file itself.
+func New(schema string, definition string) nest.DocumentValidator {
+	return func(doc map[string]interface{}) error {
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+
+		args := []string{"vet"}
+		if definition != "" {
+			args = append(args, "-d", definition)
+		}
+		args = append(args, schema, "json:", "-")
+
+		var stderr bytes.Buffer
+
+		cmd := exec.Command("cue", args...)
+		cmd.Stdin = bytes.NewReader(data)
+		cmd.Stderr = &stderr
+
+		if err := cmd.Run(); err != nil {
+			// Schema violations are reported on stderr with their paths
+			if _, ok := err.(*exec.ExitError); ok && stderr.Len() > 0 {
+				return errors.New(strings.TrimSpace(stderr.String()))
+			}
+
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,40 @@
+package cuevalidate_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/goph/nest/cuevalidate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	if _, err := exec.LookPath("cue"); err != nil {
+		t.Skip("cue is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "nest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	schema := filepath.Join(dir, "schema.cue")
+	err = ioutil.WriteFile(schema, []byte("#Config: {\n\tdatabase: port: int & >0\n}\n"), 0644)
+	require.NoError(t, err)
+
+	validate := cuevalidate.New(schema, "#Config")
+
+	err = validate(map[string]interface{}{
+		"database": map[string]interface{}{"port": 3306},
+	})
+	assert.NoError(t, err)
+
+	err = validate(map[string]interface{}{
+		"database": map[string]interface{}{"port": "mysql"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "database.port")
+}
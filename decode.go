@@ -1,6 +1,7 @@
 package nest
 
 import (
+	"context"
 	"encoding"
 	"errors"
 	"reflect"
@@ -11,34 +12,59 @@ type Decoder interface {
 	Decode(value string) error
 }
 
+// DecoderContext is implemented by types that can deserialize themselves using a context
+// (eg. when decoding involves I/O, like resolving a reference).
+type DecoderContext interface {
+	DecodeContext(ctx context.Context, value string) error
+}
+
+var (
+	decoderType         = reflect.TypeOf((*Decoder)(nil)).Elem()
+	decoderContextType  = reflect.TypeOf((*DecoderContext)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// implementsDecoder checks whether a type implements any of the decoding interfaces.
+func implementsDecoder(typ reflect.Type) bool {
+	return typ.Implements(decoderType) || typ.Implements(decoderContextType) || typ.Implements(textUnmarshalerType)
+}
+
 // canDecode checks whether a value can decode itself.
+//
+// The check relies on the type information only, so that it does not allocate.
 func canDecode(field reflect.Value) bool {
 	// struct fields cannot fail this check
 	if !field.CanInterface() {
 		return false
 	}
 
-	_, ok := field.Interface().(Decoder)
-	if !ok && field.CanAddr() {
-		_, ok = field.Addr().Interface().(Decoder)
+	typ := field.Type()
+	if implementsDecoder(typ) {
+		return true
 	}
 
-	if !ok {
-		_, ok = field.Interface().(encoding.TextUnmarshaler)
-		if !ok && field.CanAddr() {
-			_, ok = field.Addr().Interface().(encoding.TextUnmarshaler)
-		}
+	if field.CanAddr() {
+		return implementsDecoder(reflect.PtrTo(typ))
 	}
 
-	return ok
+	return false
 }
 
 // decode makes a value decode itself.
-func decode(field reflect.Value, value string) error {
+func decode(ctx context.Context, field reflect.Value, value string) error {
 	if !canDecode(field) {
 		return errors.New("value cannot decode itself")
 	}
 
+	dc, ok := field.Interface().(DecoderContext)
+	if !ok && field.CanAddr() {
+		dc, ok = field.Addr().Interface().(DecoderContext)
+	}
+
+	if ok {
+		return dc.DecodeContext(ctx, value)
+	}
+
 	d, ok := field.Interface().(Decoder)
 	if !ok && field.CanAddr() {
 		d, ok = field.Addr().Interface().(Decoder)
@@ -1,6 +1,7 @@
 package nest
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
@@ -36,6 +37,20 @@ func (d *decodable) getValue() string {
 	return d.value
 }
 
+type contextDecodable struct {
+	value string
+}
+
+func (d *contextDecodable) DecodeContext(ctx context.Context, value string) error {
+	d.value = value
+
+	return ctx.Err()
+}
+
+func (d *contextDecodable) getValue() string {
+	return d.value
+}
+
 func TestCanDecode(t *testing.T) {
 	tests := map[string]struct {
 		v         interface{}
@@ -49,6 +64,14 @@ func TestCanDecode(t *testing.T) {
 			&unmarshalable{},
 			true,
 		},
+		"context_decodable": {
+			&contextDecodable{},
+			true,
+		},
+		"string": {
+			new(string),
+			false,
+		},
 	}
 
 	for name, test := range tests {
@@ -62,8 +85,9 @@ func TestDecode(t *testing.T) {
 	tests := map[string]interface {
 		getValue() string
 	}{
-		"decodable": &decodable{},
-		"unmarshalable": &unmarshalable{},
+		"decodable":         &decodable{},
+		"unmarshalable":     &unmarshalable{},
+		"context_decodable": &contextDecodable{},
 	}
 
 	for name, test := range tests {
@@ -71,7 +95,7 @@ func TestDecode(t *testing.T) {
 			field := reflect.ValueOf(test)
 
 			if assert.True(t, canDecode(field)) {
-				err := decode(field, "data")
+				err := decode(context.Background(), field, "data")
 				require.NoError(t, err)
 				assert.Equal(t, "data", test.getValue())
 			}
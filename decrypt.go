@@ -0,0 +1,33 @@
+package nest
+
+import (
+	"strings"
+)
+
+// Decryptor is implemented by types that can decrypt configuration values (eg. using KMS or age).
+type Decryptor interface {
+	Decrypt(ciphertext string) (string, error)
+}
+
+// decryptValue decrypts a value prefixed with a registered scheme (eg. "kms:ciphertext").
+// Values without a registered scheme are returned as is.
+func decryptValue(decryptors map[string]Decryptor, value string) (string, error) {
+	i := strings.Index(value, ":")
+	if i < 1 {
+		return value, nil
+	}
+
+	decryptor, ok := decryptors[value[:i]]
+	if !ok {
+		return value, nil
+	}
+
+	return decryptor.Decrypt(value[i+1:])
+}
+
+// isEncryptedValue checks whether a value is prefixed with the scheme of a registered decryptor.
+func isEncryptedValue(decryptors map[string]Decryptor, value string) bool {
+	i := strings.Index(value, ":")
+
+	return i > 0 && decryptors[value[:i]] != nil
+}
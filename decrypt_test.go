@@ -0,0 +1,53 @@
+package nest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upperDecryptor "decrypts" values by converting them to upper case.
+type upperDecryptor struct{}
+
+func (upperDecryptor) Decrypt(ciphertext string) (string, error) {
+	if ciphertext == "" {
+		return "", errors.New("empty ciphertext")
+	}
+
+	return strings.ToUpper(ciphertext), nil
+}
+
+func TestDecryptValue(t *testing.T) {
+	decryptors := map[string]Decryptor{
+		"kms": upperDecryptor{},
+	}
+
+	tests := map[string]string{
+		"kms:secret":        "SECRET",
+		"plain":             "plain",
+		":secret":           ":secret",
+		"age:secret":        "age:secret",
+		"http://kms:secret": "http://kms:secret",
+	}
+
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			actual, err := decryptValue(decryptors, input)
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestDecryptValue_Error(t *testing.T) {
+	decryptors := map[string]Decryptor{
+		"kms": upperDecryptor{},
+	}
+
+	_, err := decryptValue(decryptors, "kms:")
+	require.Error(t, err)
+	assert.EqualError(t, err, "empty ciphertext")
+}
@@ -1,8 +1,11 @@
 package nest
 
 import (
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // unsupportedTypes is a list of types that cannot be configured at the moment.
@@ -15,95 +18,424 @@ var unsupportedTypes = map[reflect.Kind]bool{
 	reflect.Interface:     true,
 	reflect.Map:           true,
 	reflect.Ptr:           true,
-	reflect.Slice:         true,
 	reflect.UnsafePointer: true,
 }
 
+// isUnsupportedType checks whether a type cannot be configured at the moment.
+// Slices are supported when their elements can be configured (except for slices of slices and structs,
+// slices of structs can be loaded from indexed environment variables though, see isIndexedType).
+// Maps with string keys are supported when their values can be configured (except for slices, maps and structs).
+func isUnsupportedType(typ reflect.Type) bool {
+	if implementsDecoder(typ) || implementsDecoder(reflect.PtrTo(typ)) {
+		return false
+	}
+
+	if typ.Kind() == reflect.Slice {
+		elem := typ.Elem()
+
+		if elem.Kind() == reflect.Slice || elem.Kind() == reflect.Struct {
+			return implementsDecoder(reflect.PtrTo(elem)) == false
+		}
+
+		return isUnsupportedType(elem)
+	}
+
+	if typ.Kind() == reflect.Map {
+		elem := typ.Elem()
+
+		if typ.Key().Kind() != reflect.String {
+			return true
+		}
+
+		if elem.Kind() == reflect.Slice || elem.Kind() == reflect.Map || elem.Kind() == reflect.Struct {
+			return implementsDecoder(reflect.PtrTo(elem)) == false
+		}
+
+		return isUnsupportedType(elem)
+	}
+
+	return unsupportedTypes[typ.Kind()]
+}
+
+// isNeverConfigurable checks whether a type can never be configured (channels, functions and unsafe pointers
+// or containers of them), as opposed to types which might be supported in the future.
+func isNeverConfigurable(typ reflect.Type) bool {
+	return isNeverConfigurableType(typ, make(map[reflect.Type]bool))
+}
+
+// isNeverConfigurableType is the recursive part of isNeverConfigurable.
+// Visited types are tracked, so that self-referential types (eg. type Tree map[string]Tree) terminate.
+func isNeverConfigurableType(typ reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[typ] {
+		return false
+	}
+
+	visited[typ] = true
+
+	if implementsDecoder(typ) || implementsDecoder(reflect.PtrTo(typ)) {
+		return false
+	}
+
+	switch typ.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return true
+
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Ptr:
+		return isNeverConfigurableType(typ.Elem(), visited)
+	}
+
+	return false
+}
+
 type fieldDefinition struct {
 	key   string
 	field reflect.Value
 
+	// Passes the loaded value to the setter of the field (see FieldSetter)
+	setter func() error
+
+	// Slice of structs loaded from indexed environment variables (see applyIndexed)
+	indexed bool
+
+	// Former keys of the field
+	keyAliases []string
+
+	// Date after which the former keys are rejected
+	aliasSunset time.Time
+
 	hasOverride   bool
 	overrideValue interface{}
 
 	hasFlag   bool
 	flagAlias string
 
-	hasEnv   bool
-	envAlias string
+	hasEnv     bool
+	envAlias   string
+	envAliases []string
 
 	hasDefault   bool
 	defaultValue string
 
+	// Default values of the modes by lower cased mode name
+	modeDefaults map[string]string
+
 	required bool
 
-	usage string
+	secret bool
+	ttl    string
+
+	template bool
+
+	// Trim surrounding whitespace (eg. trailing newlines of mounted secret files)
+	trim bool
+
+	// Strip surrounding quotes (eg. multi-line values quoted in .env style files)
+	unquote bool
+
+	// Separator of the elements of slice values
+	separator string
+
+	usage   string
+	section string
+	example string
+	unit    string
+	format  string
+	weight  int
+
+	choices []string
+
+	hidden bool
+
+	customTags []customTag
+
+	// SourceInfo fields reporting where the value comes from
+	sourceInfos []reflect.Value
+
+	metric bool
+}
+
+// isRequired checks whether a required tag makes a field required:
+// it's either a boolean or a comma separated list of modes (eg. required:"production,staging").
+func isRequired(value string, mode string) bool {
+	if required, err := strconv.ParseBool(value); err == nil {
+		return required
+	}
+
+	return matchesMode(value, mode)
+}
+
+// matchesMode checks whether a comma separated list of modes contains the current one (case-insensitively).
+func matchesMode(modes string, mode string) bool {
+	if mode == "" {
+		return false
+	}
+
+	for _, m := range strings.Split(modes, ",") {
+		if strings.EqualFold(strings.TrimSpace(m), mode) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getModeDefaults collects the mode specific default values of a field (eg. default.production:"100").
+func getModeDefaults(tag reflect.StructTag) map[string]string {
+	var defaults map[string]string
+
+	for _, key := range tagKeys(tag) {
+		if !strings.HasPrefix(key, TagDefault+".") || len(key) == len(TagDefault)+1 {
+			continue
+		}
+
+		if defaults == nil {
+			defaults = make(map[string]string)
+		}
+
+		value, _ := tag.Lookup(key)
+		defaults[strings.ToLower(key[len(TagDefault)+1:])] = value
+	}
+
+	return defaults
 }
 
-func getDefinitions(structRef reflect.Value) []fieldDefinition {
-	return getDefinitionsForStruct(structRef, "")
+// cleanValue applies the trimming and unquoting configured for the field to a raw value.
+func (def fieldDefinition) cleanValue(value string) string {
+	if def.trim {
+		value = strings.TrimSpace(value)
+	}
+
+	if def.unquote {
+		value = unquoteValue(value)
+	}
+
+	return value
 }
 
-func getDefinitionsForStruct(structRef reflect.Value, prefix string) []fieldDefinition {
+// definitionLimits restricts the size of configuration structs.
+// Zero values mean no limit.
+type definitionLimits struct {
+	maxDepth  int
+	maxFields int
+}
+
+// definitionOptions controls the definition pass.
+type definitionOptions struct {
+	limits definitionLimits
+
+	// Split words in field names (unless disabled by the split_words tag)
+	splitWords bool
+
+	// Naming conventions of generated names (unless disabled by the split_words tag)
+	snakeEnv    bool
+	spinalFlags bool
+
+	// Delimiter joining the keys of nested structs (defaults to ".")
+	keyDelimiter string
+
+	// Merge the fields of embedded structs into the parent namespace (unless they have a prefix tag)
+	mergeEmbedded bool
+
+	// Honor kelseyhightower/envconfig tags and semantics
+	envconfig bool
+
+	// Trim surrounding whitespace from values (unless disabled by the trim tag)
+	trimSpace bool
+
+	// Mode the application runs in (eg. production), matched against mode specific tags
+	mode string
+}
+
+// definitionState holds the state of the definition pass.
+type definitionState struct {
+	options definitionOptions
+
+	// Types being processed, used to detect recursive types (eg. tree shaped configs)
+	visited map[reflect.Type]bool
+
+	// Keys of fields ignored by their parent (eg. fields of embedded third-party types)
+	ignoredKeys map[string]bool
+
+	// Help section of the fields of the current struct
+	section string
+
+	depth  int
+	fields int
+}
+
+func getDefinitions(structRef reflect.Value) ([]fieldDefinition, error) {
+	return getLimitedDefinitions(structRef, definitionLimits{})
+}
+
+// getLimitedDefinitions collects the field definitions of a struct and returns an error when the struct exceeds the limits.
+func getLimitedDefinitions(structRef reflect.Value, limits definitionLimits) ([]fieldDefinition, error) {
+	return getDefinitionsWithOptions(structRef, definitionOptions{limits: limits})
+}
+
+// getDefinitionsWithOptions collects the field definitions of a struct.
+func getDefinitionsWithOptions(structRef reflect.Value, options definitionOptions) ([]fieldDefinition, error) {
+	if options.keyDelimiter == "" {
+		options.keyDelimiter = "."
+	}
+
+	state := &definitionState{
+		options:     options,
+		visited:     make(map[reflect.Type]bool),
+		ignoredKeys: make(map[string]bool),
+	}
+
+	return getDefinitionsForStruct(structRef, "", options.splitWords, state)
+}
+
+// getDefinitionsForStruct collects the field definitions of a struct.
+// Word splitting is inherited from the parent struct unless a field overrides it.
+func getDefinitionsForStruct(structRef reflect.Value, prefix string, splitWordsDefault bool, state *definitionState) ([]fieldDefinition, error) {
 	structType := structRef.Type()
 
+	if state.visited[structType] {
+		return nil, fmt.Errorf("recursive type %s detected at %s", structType, prefix)
+	}
+
+	if state.options.limits.maxDepth > 0 && state.depth > state.options.limits.maxDepth {
+		return nil, fmt.Errorf("nesting depth exceeds the limit of %d at %s", state.options.limits.maxDepth, prefix)
+	}
+
+	state.visited[structType] = true
+	state.depth++
+
+	defer func() {
+		delete(state.visited, structType)
+		state.depth--
+	}()
+
 	var keyPrefix string
 	if prefix != "" {
-		keyPrefix = prefix + "."
+		keyPrefix = prefix + state.options.keyDelimiter
 	}
 
 	var flagPrefix string
 	if prefix != "" {
-		flagPrefix = strings.ToLower(strings.Replace(prefix, ".", "-", -1)) + "-"
+		flagPrefix = joinPrefix(prefix, state.options.keyDelimiter, "-", state.options.spinalFlags) + "-"
 	}
 
 	var envPrefix string
 	if prefix != "" {
-		envPrefix = strings.ToLower(strings.Replace(prefix, ".", "_", -1)) + "_"
+		envPrefix = joinPrefix(prefix, state.options.keyDelimiter, "_", state.options.snakeEnv) + "_"
 	}
 
 	var definitions []fieldDefinition
+	var annotations []sourceAnnotation
 
 	// Gather configuration definition information
 	for i := 0; i < structType.NumField(); i++ {
 		structField := structType.Field(i)
 		field := structRef.Field(i)
 
-		// Ignore unexported field
-		if isExported(structField.Name) == false {
+		// Manually ignored field (or the whole struct)
+		if value, ok := structField.Tag.Lookup(TagIgnored); ok && isTrue(value) {
 			continue
 		}
 
-		// Manually ignored field
-		if value, ok := structField.Tag.Lookup(TagIgnored); ok && isTrue(value) {
+		// Field ignored by the parent struct
+		if state.ignoredKeys[keyPrefix+structField.Name] {
+			continue
+		}
+
+		// Fields with a setter are loaded into a temporary value, which is passed to the setter
+		setter, err := getFieldSetter(structRef, structField, keyPrefix+structField.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if setter != nil {
+			field = reflect.New(structField.Type).Elem()
+		}
+
+		setterValue := field
+
+		// Ignore unexported field (unless it has a setter or it's an embedded struct, because it's exported fields are promoted)
+		if isExported(structField.Name) == false && !isEmbeddedStruct(structField) && setter == nil {
 			continue
 		}
 
+		// Source annotations are populated after loading the field they reference
+		if structField.Type == sourceInfoType {
+			if value := structField.Tag.Get(TagSourceOf); value != "" {
+				annotations = append(annotations, sourceAnnotation{
+					key:   keyPrefix + value,
+					field: field,
+				})
+			}
+
+			continue
+		}
+
+		// Word splitting can be enabled or disabled for the field (and it's children)
+		splitWordsEnabled := splitWordsDefault
+		splitWordsTag, hasSplitWordsTag := structField.Tag.Lookup(TagSplitWords)
+		if hasSplitWordsTag {
+			splitWordsEnabled = isTrue(splitWordsTag)
+		}
+
 		// Resolve pointer to it's actual type
 		for field.Kind() == reflect.Ptr {
 			// Set to zero value when field is nil
 			if field.IsNil() {
+				// Nil pointers to unexported embedded structs cannot be allocated
+				if !field.CanSet() {
+					break
+				}
+
 				field.Set(reflect.New(field.Type().Elem()))
 			}
 
 			field = field.Elem()
 		}
 
+		// Ignore pointers which could not be resolved
+		if field.Kind() == reflect.Ptr {
+			continue
+		}
+
+		// Structs decoding themselves are leaves, unless the decode tag says otherwise
+		decodeStruct := field.Kind() == reflect.Struct && canDecode(field)
+		if value, ok := structField.Tag.Lookup(TagDecode); ok {
+			decodeStruct = decodeStruct && isTrue(value)
+		} else if decodeStruct && hasConfigurationTags(field.Type()) {
+			return nil, fmt.Errorf(
+				"ambiguous field %s%s: %s implements Decoder and has configuration tags, use the %s tag to choose",
+				keyPrefix,
+				structField.Name,
+				field.Type(),
+				TagDecode,
+			)
+		}
+
+		if setter != nil && field.Kind() == reflect.Struct && !decodeStruct {
+			return nil, fmt.Errorf("field %s%s has a setter, but it's a struct: setters are only supported for values", keyPrefix, structField.Name)
+		}
+
 		// Process child struct fields
-		if field.Kind() == reflect.Struct && !canDecode(field) {
+		if field.Kind() == reflect.Struct && !decodeStruct {
 			prefix := prefix
-			value, ok := structField.Tag.Lookup(TagPrefix)
-			if value != "" {
+
+			switch value, ok := structField.Tag.Lookup(TagPrefix); {
+			case ok && value == "":
+				// Explicitly empty prefix, merge the fields into the parent namespace
+
+			case ok:
 				prefix = keyPrefix + value
-			}
 
-			// No prefix is provided, guess the prefix from the struct name
-			if !ok {
+			case structField.Anonymous && state.options.mergeEmbedded:
+				// Embedded structs are merged into the parent namespace, just like Go promotes their fields
+
+			default:
+				// No prefix is provided, guess the prefix from the struct name
 				name := structField.Name
 
 				// Try to split words in the struct name if possible
-				if v, ok := structField.Tag.Lookup(TagSplitWords); ok && isTrue(v) {
-					v = splitWords(name, ".")
+				if splitWordsEnabled {
+					v := splitWords(name, state.options.keyDelimiter)
 					if v != "" {
 						name = v
 					}
@@ -112,22 +444,99 @@ func getDefinitionsForStruct(structRef reflect.Value, prefix string) []fieldDefi
 				prefix = keyPrefix + name
 			}
 
-			structDefinitions := getDefinitionsForStruct(field, prefix)
+			// Ignore some of the child fields (useful for embedded types which cannot be tagged)
+			if value := structField.Tag.Get(TagIgnoreFields); value != "" {
+				var childKeyPrefix string
+				if prefix != "" {
+					childKeyPrefix = prefix + state.options.keyDelimiter
+				}
+
+				for _, name := range strings.Split(value, ",") {
+					state.ignoredKeys[childKeyPrefix+strings.TrimSpace(name)] = true
+				}
+			}
+
+			// Group the fields of the struct in a help section (child structs inherit it)
+			section := state.section
+			if value, ok := structField.Tag.Lookup(TagSection); ok {
+				state.section = value
+			}
+
+			structDefinitions, err := getDefinitionsForStruct(field, prefix, splitWordsEnabled, state)
+			state.section = section
+			if err != nil {
+				return nil, err
+			}
+
 			definitions = append(definitions, structDefinitions...)
 
 			continue
 		}
 
+		// Slices of structs are loaded from indexed environment variables (eg. SERVERS_0_HOST)
+		_, hasEnvTag := structField.Tag.Lookup(TagEnvironment)
+		indexed := isIndexedType(field.Type()) && (hasEnvTag || state.options.envconfig)
+
 		// Ignore unsupported field
-		if _, unsupported := unsupportedTypes[field.Kind()]; unsupported {
+		if isUnsupportedType(field.Type()) && !indexed {
+			// Tagging a field which can never be configured is always a mistake
+			if tags := configurationTagKeys(structField.Tag); len(tags) > 0 {
+				if isNeverConfigurable(field.Type()) {
+					return nil, fmt.Errorf(
+						"field %s%s of type %s cannot be configured, but it has configuration tags (%s): remove them or add %s:\"true\"",
+						keyPrefix,
+						structField.Name,
+						field.Type(),
+						strings.Join(tags, ", "),
+						TagIgnored,
+					)
+				}
+			}
+
 			continue
 		}
 
 		def := fieldDefinition{
-			key:   keyPrefix + structField.Name,
-			field: field,
+			key:     keyPrefix + structField.Name,
+			field:   field,
+			indexed: indexed,
+
+			usage:   structField.Tag.Get(TagUsage),
+			section: state.section,
+			example: structField.Tag.Get(TagExample),
+			unit:    structField.Tag.Get(TagUnit),
+			format:  structField.Tag.Get(TagFormat),
+		}
+
+		if setter != nil {
+			def.setter = func() error { return setter(setterValue) }
+		}
+
+		// Set former keys (if any)
+		if value, ok := structField.Tag.Lookup(TagAlias); ok && value != "" {
+			for _, alias := range strings.Split(value, ",") {
+				def.keyAliases = append(def.keyAliases, strings.TrimSpace(alias))
+			}
+		}
+
+		// Set the sunset date of the former keys (if any)
+		if value, ok := structField.Tag.Lookup(TagAliasDeprecatedAfter); ok {
+			sunset, err := time.Parse(aliasDateLayout, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s for field %s: %v", TagAliasDeprecatedAfter, def.key, err)
+			}
+
+			def.aliasSunset = sunset
+		}
+
+		// Set help section of the field
+		if value, ok := structField.Tag.Lookup(TagSection); ok {
+			def.section = value
+		}
 
-			usage: structField.Tag.Get(TagUsage),
+		// Fall back to envconfig's usage tag
+		if def.usage == "" && state.options.envconfig {
+			def.usage = structField.Tag.Get(TagDesc)
 		}
 
 		// Set value override
@@ -146,8 +555,8 @@ func getDefinitionsForStruct(structRef reflect.Value, prefix string) []fieldDefi
 				value = lowerFirst(structField.Name)
 
 				// Try to split words in the struct name if possible
-				if v, ok := structField.Tag.Lookup(TagSplitWords); ok && isTrue(v) {
-					v = splitWords(value, "-")
+				if splitWordsEnabled || (state.options.spinalFlags && !hasSplitWordsTag) {
+					v := splitWords(value, "-")
 					if v != "" {
 						value = v
 					}
@@ -157,15 +566,21 @@ func getDefinitionsForStruct(structRef reflect.Value, prefix string) []fieldDefi
 			def.flagAlias = flagPrefix + value
 		}
 
+		// Every field is an environment variable in envconfig compatibility mode
+		envValue, hasEnv := structField.Tag.Lookup(TagEnvironment)
+		if !hasEnv && state.options.envconfig {
+			envValue, hasEnv = structField.Tag.Get(TagEnvconfig), true
+		}
+
 		// Map environment variable to field
-		if value, ok := structField.Tag.Lookup(TagEnvironment); ok {
+		if value, ok := envValue, hasEnv; ok {
 			def.hasEnv = true
 
 			// An environment variable alias is provided
 			if value != "" {
 				def.envAlias = strings.ToUpper(envPrefix + value)
-			} else if v, ok := structField.Tag.Lookup(TagSplitWords); ok && isTrue(v) { // Try to split words in the struct name if possible
-				v = splitWords(structField.Name, "_")
+			} else if splitWordsEnabled || (state.options.snakeEnv && !hasSplitWordsTag) { // Try to split words in the struct name if possible
+				v := splitWords(structField.Name, "_")
 				if v != "" {
 					def.envAlias = strings.ToUpper(envPrefix + v)
 				}
@@ -180,13 +595,165 @@ func getDefinitionsForStruct(structRef reflect.Value, prefix string) []fieldDefi
 			def.defaultValue = value
 		}
 
+		// Mode specific defaults (eg. default.production:"100") take precedence over the default tag
+		def.modeDefaults = getModeDefaults(structField.Tag)
+		if value, ok := def.modeDefaults[strings.ToLower(state.options.mode)]; ok && state.options.mode != "" {
+			def.hasDefault = true
+			def.defaultValue = value
+		}
+
 		// Check if the field is required
-		if value, ok := structField.Tag.Lookup(TagRequired); ok && isTrue(value) {
+		if value, ok := structField.Tag.Lookup(TagRequired); ok && isRequired(value, state.options.mode) {
 			def.required = true
 		}
 
+		// Check if the field holds sensitive data
+		if value, ok := structField.Tag.Lookup(TagSecret); (ok && isTrue(value)) || field.Type() == secretType || field.Type() == secretStringType {
+			def.secret = true
+		}
+
+		// Set secret rotation interval (if any)
+		if value, ok := structField.Tag.Lookup(TagTTL); ok {
+			def.ttl = value
+		}
+
+		// Set allowed values (if any)
+		if value, ok := structField.Tag.Lookup(TagEnum); ok && value != "" {
+			for _, choice := range strings.Split(value, ",") {
+				def.choices = append(def.choices, strings.TrimSpace(choice))
+			}
+		}
+
+		// Set help output weight (if any)
+		if value, ok := structField.Tag.Lookup(TagWeight); ok {
+			weight, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid weight for field %s: %v", def.key, err)
+			}
+
+			def.weight = weight
+		}
+
+		// Check if the value should be exported as a metric
+		if value, ok := structField.Tag.Lookup(TagMetric); ok && isTrue(value) {
+			def.metric = true
+		}
+
+		// Check if the value should be expanded as a template
+		if value, ok := structField.Tag.Lookup(TagTemplate); ok && isTrue(value) {
+			def.template = true
+		}
+
+		// Check if surrounding whitespace should be trimmed from the value
+		def.trim = state.options.trimSpace
+		if value, ok := structField.Tag.Lookup(TagTrim); ok {
+			def.trim = isTrue(value)
+		}
+
+		if indexed && def.hasFlag {
+			return nil, fmt.Errorf("field %s is a slice of structs: it can only be loaded from indexed environment variables", def.key)
+		}
+
+		// Set the separator of slice elements (and map entries)
+		if (field.Kind() == reflect.Slice || field.Kind() == reflect.Map) && !indexed {
+			def.separator = defaultSeparator
+
+			if value, ok := structField.Tag.Lookup(TagSeparator); ok && value != "" {
+				def.separator = value
+			}
+		}
+
+		// Check if surrounding quotes should be stripped from the value
+		if value, ok := structField.Tag.Lookup(TagUnquote); ok && isTrue(value) {
+			def.unquote = true
+		}
+
+		// Let custom tag handlers modify the definition
+		def.customTags = getCustomTags(structField.Tag.Lookup)
+
+		def, err = handleCustomTags(def)
+		if err != nil {
+			return nil, err
+		}
+
+		state.fields++
+		if state.options.limits.maxFields > 0 && state.fields > state.options.limits.maxFields {
+			return nil, fmt.Errorf("number of fields exceeds the limit of %d at %s", state.options.limits.maxFields, def.key)
+		}
+
 		definitions = append(definitions, def)
 	}
 
-	return definitions
+	// Attach source annotations to the fields they reference
+	for _, annotation := range annotations {
+		found := false
+
+		for i := range definitions {
+			if definitions[i].key == annotation.key {
+				definitions[i].sourceInfos = append(definitions[i].sourceInfos, annotation.field)
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("%s tag references unknown field %s", TagSourceOf, annotation.key)
+		}
+	}
+
+	return definitions, nil
+}
+
+// sourceAnnotation is a SourceInfo field waiting to be attached to the field it references.
+type sourceAnnotation struct {
+	key   string
+	field reflect.Value
+}
+
+// isEmbeddedStruct checks whether a struct field is an embedded struct or struct pointer.
+func isEmbeddedStruct(structField reflect.StructField) bool {
+	if !structField.Anonymous {
+		return false
+	}
+
+	typ := structField.Type
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	return typ.Kind() == reflect.Struct
+}
+
+// hasConfigurationTags checks whether any field of a struct type has configuration tags.
+func hasConfigurationTags(structType reflect.Type) bool {
+	tags := []string{TagDefault, TagRequired, TagPrefix, TagEnvironment, TagFlag, TagUsage}
+
+	for i := 0; i < structType.NumField(); i++ {
+		for _, tag := range tags {
+			if _, ok := structType.Field(i).Tag.Lookup(tag); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// joinPrefix converts a key prefix to a flag or environment variable prefix.
+// When splitting is enabled, words in each segment of the prefix are split as well.
+func joinPrefix(prefix string, delimiter string, glue string, split bool) string {
+	segments := strings.Split(prefix, delimiter)
+
+	for i, segment := range segments {
+		if split {
+			if v := splitWords(segment, glue); v != "" {
+				segment = v
+			}
+		}
+
+		segments[i] = strings.ToLower(segment)
+	}
+
+	return strings.Join(segments, glue)
 }
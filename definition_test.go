@@ -3,8 +3,10 @@ package nest
 import (
 	"reflect"
 	"testing"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type Decodable string
@@ -52,7 +54,8 @@ func TestField_IgnoreUnexportedField(t *testing.T) {
 	ref := reflect.ValueOf(c)
 	var expected []fieldDefinition
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -65,10 +68,81 @@ func TestField_Ignored(t *testing.T) {
 	ref := reflect.ValueOf(c)
 	var expected []fieldDefinition
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
+func TestField_NeverConfigurable(t *testing.T) {
+	type config struct {
+		Handler  func()         `env:"" usage:"request handler"`
+		Events   chan string    `default:"events"`
+		Pointer  unsafe.Pointer `flag:""`
+		Handlers []func()       `required:"true"`
+	}
+
+	_, err := getDefinitions(reflect.ValueOf(&config{}).Elem())
+	require.EqualError(t, err, `field Handler of type func() cannot be configured, but it has configuration tags (env, usage): remove them or add ignored:"true"`)
+
+	tests := map[string]interface{}{
+		"chan": &struct {
+			Events chan string `default:"events"`
+		}{},
+		"unsafe pointer": &struct {
+			Pointer unsafe.Pointer `flag:""`
+		}{},
+		"slice of funcs": &struct {
+			Handlers []func() `required:"true"`
+		}{},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := getDefinitions(reflect.ValueOf(test).Elem())
+			assert.Error(t, err)
+		})
+	}
+
+	// Untagged and ignored fields are skipped silently
+	type skipped struct {
+		Handler  func()
+		Events   chan string `ignored:"true" env:""`
+		Callback func()      `json:"callback"`
+		Complex  complex128  `env:""`
+	}
+
+	actual, err := getDefinitions(reflect.ValueOf(&skipped{}).Elem())
+	require.NoError(t, err)
+	assert.Empty(t, actual)
+}
+
+type recursiveMap map[string]recursiveMap
+
+type recursiveSlice []recursiveSlice
+
+func TestField_NeverConfigurable_RecursiveTypes(t *testing.T) {
+	type config struct {
+		Tree  recursiveMap
+		List  recursiveSlice
+		Value string
+	}
+
+	actual, err := getDefinitions(reflect.ValueOf(&config{}).Elem())
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	assert.Equal(t, "Value", actual[0].key)
+
+	type tagged struct {
+		Tree recursiveMap `env:""`
+	}
+
+	_, err = getDefinitions(reflect.ValueOf(&tagged{}).Elem())
+	require.NoError(t, err)
+
+	assert.False(t, isNeverConfigurable(reflect.TypeOf(recursiveMap{})))
+	assert.True(t, isNeverConfigurable(reflect.TypeOf(map[string][]func(){})))
+}
+
 func TestField_Required(t *testing.T) {
 	type config struct {
 		Value string `required:"true"`
@@ -85,7 +159,8 @@ func TestField_Required(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -108,7 +183,8 @@ func TestField_Overrides(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -129,7 +205,8 @@ func TestField_Flag(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -150,7 +227,8 @@ func TestField_FlagWithAlias(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -171,7 +249,8 @@ func TestField_FlagWithUpperCaseFirstAlias(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -192,7 +271,8 @@ func TestField_FlagSplitWords(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -213,7 +293,8 @@ func TestField_Environment(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -234,7 +315,8 @@ func TestField_EnvironmentWithAlias(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -255,7 +337,8 @@ func TestField_EnvironmentSplitWords(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -276,7 +359,8 @@ func TestField_Default(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -301,7 +385,8 @@ func TestField_ChildStruct(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -326,7 +411,8 @@ func TestField_ChildStruct_Prefix(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -351,7 +437,8 @@ func TestField_ChildStruct_Flag(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -376,7 +463,8 @@ func TestField_ChildStruct_Prefix_Flag(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -405,7 +493,8 @@ func TestField_ChildStructMulti_Flag(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -434,7 +523,8 @@ func TestField_ChildStructMulti_Prefix_Flag(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -459,7 +549,8 @@ func TestField_ChildStruct_Environment(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -484,7 +575,79 @@ func TestField_ChildStruct_Prefix_Environment(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestField_ChildStruct_EmptyPrefix(t *testing.T) {
+	type subconfig struct {
+		Value string `flag:"" env:""`
+	}
+
+	type config struct {
+		Sconfig subconfig `prefix:""`
+		Other   struct {
+			Sconfig subconfig `prefix:""`
+		}
+	}
+
+	c := config{}
+	ref := reflect.ValueOf(c)
+	expected := []fieldDefinition{
+		{
+			key:   "Value",
+			field: ref.Field(0).Field(0),
+
+			hasFlag:   true,
+			flagAlias: "value",
+
+			hasEnv:   true,
+			envAlias: "VALUE",
+		},
+		{
+			key:   "Other.Value",
+			field: ref.Field(1).Field(0).Field(0),
+
+			hasFlag:   true,
+			flagAlias: "other-value",
+
+			hasEnv:   true,
+			envAlias: "OTHER_VALUE",
+		},
+	}
+
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestField_ChildStruct_NoPrefixTag(t *testing.T) {
+	type subconfig struct {
+		Value string `flag:"" env:""`
+	}
+
+	type config struct {
+		Sconfig subconfig
+	}
+
+	c := config{}
+	ref := reflect.ValueOf(c)
+	expected := []fieldDefinition{
+		{
+			key:   "Sconfig.Value",
+			field: ref.Field(0).Field(0),
+
+			hasFlag:   true,
+			flagAlias: "sconfig-value",
+
+			hasEnv:   true,
+			envAlias: "SCONFIG_VALUE",
+		},
+	}
+
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -509,7 +672,8 @@ func TestField_ChildStruct_EnvironmentWithAlias(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -538,7 +702,8 @@ func TestField_ChildStructMulti_EnvironmentWithAlias(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -567,7 +732,8 @@ func TestField_ChildStructMulti_Prefix_EnvironmentWithAlias(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -592,7 +758,8 @@ func TestField_EmbeddedStruct(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -628,7 +795,8 @@ func TestField_EmbeddedStruct_Prefix(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -653,7 +821,8 @@ func TestField_EmbeddedStruct_Flag(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -678,7 +847,8 @@ func TestField_EmbeddedStruct_Prefix_Flag(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -707,7 +877,8 @@ func TestField_EmbeddedStructMulti_Flag(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -736,7 +907,8 @@ func TestField_EmbeddedStructMulti_Prefix_Flag(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -761,7 +933,8 @@ func TestField_EmbeddedStruct_Environment(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -786,7 +959,8 @@ func TestField_EmbeddedStruct_Prefix_Environment(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -811,7 +985,8 @@ func TestField_EmbeddedStruct_EnvironmentWithAlias(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -840,7 +1015,8 @@ func TestField_EmbeddedStructMulti_EnvironmentWithAlias(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -865,7 +1041,8 @@ func TestField_EmbeddedStruct_Prefix_EnvironmentWithAlias(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -894,7 +1071,8 @@ func TestField_EmbeddedStructMulti_Prefix_EnvironmentWithAlias(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref)
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -923,7 +1101,8 @@ func TestField_Decode(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref.Elem())
+	actual, err := getDefinitions(ref.Elem())
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
 
@@ -952,6 +1131,349 @@ func TestField_StructDecode(t *testing.T) {
 		},
 	}
 
-	actual := getDefinitions(ref.Elem())
+	actual, err := getDefinitions(ref.Elem())
+	require.NoError(t, err)
 	assert.Equal(t, expected, actual)
 }
+
+type embeddedSubconfig struct {
+	Value string `default:"default"`
+}
+
+func TestField_EmbeddedStructPointer(t *testing.T) {
+	type Subconfig struct {
+		Value string `default:"default"`
+	}
+
+	type config struct {
+		*Subconfig
+	}
+
+	c := config{}
+	ref := reflect.ValueOf(&c).Elem()
+
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
+
+	require.NotNil(t, c.Subconfig)
+
+	expected := []fieldDefinition{
+		{
+			key:   "Subconfig.Value",
+			field: ref.Field(0).Elem().Field(0),
+
+			hasDefault:   true,
+			defaultValue: "default",
+		},
+	}
+
+	assert.Equal(t, expected, actual)
+}
+
+func TestField_EmbeddedUnexportedStruct(t *testing.T) {
+	type config struct {
+		embeddedSubconfig
+	}
+
+	c := config{}
+	ref := reflect.ValueOf(&c).Elem()
+	expected := []fieldDefinition{
+		{
+			key:   "embeddedSubconfig.Value",
+			field: ref.Field(0).Field(0),
+
+			hasDefault:   true,
+			defaultValue: "default",
+		},
+	}
+
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestField_EmbeddedUnexportedStructPointer(t *testing.T) {
+	type config struct {
+		*embeddedSubconfig
+	}
+
+	c := config{}
+	ref := reflect.ValueOf(&c).Elem()
+	var expected []fieldDefinition
+
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	c = config{
+		embeddedSubconfig: &embeddedSubconfig{},
+	}
+	expected = []fieldDefinition{
+		{
+			key:   "embeddedSubconfig.Value",
+			field: ref.Field(0).Elem().Field(0),
+
+			hasDefault:   true,
+			defaultValue: "default",
+		},
+	}
+
+	actual, err = getDefinitions(ref)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+type recursiveConfig struct {
+	Value string
+
+	Children struct {
+		Left  *recursiveConfig
+		Right *recursiveConfig
+	}
+}
+
+func TestField_RecursiveType(t *testing.T) {
+	c := recursiveConfig{}
+	ref := reflect.ValueOf(&c).Elem()
+
+	_, err := getDefinitions(ref)
+	require.Error(t, err)
+	assert.EqualError(t, err, "recursive type nest.recursiveConfig detected at Children.Left")
+}
+
+func TestField_RepeatedType(t *testing.T) {
+	type Subconfig struct {
+		Value string
+	}
+
+	type config struct {
+		First  Subconfig
+		Second *Subconfig
+	}
+
+	c := config{}
+	ref := reflect.ValueOf(&c).Elem()
+
+	actual, err := getDefinitions(ref)
+	require.NoError(t, err)
+	assert.Len(t, actual, 2)
+}
+
+func TestField_MaxDepth(t *testing.T) {
+	type Subsubconfig struct {
+		Value string
+	}
+
+	type Subconfig struct {
+		Subsubconfig Subsubconfig
+	}
+
+	type config struct {
+		Subconfig Subconfig
+	}
+
+	c := config{}
+	ref := reflect.ValueOf(&c).Elem()
+
+	actual, err := getLimitedDefinitions(ref, definitionLimits{maxDepth: 2})
+	require.NoError(t, err)
+	assert.Len(t, actual, 1)
+
+	_, err = getLimitedDefinitions(ref, definitionLimits{maxDepth: 1})
+	require.Error(t, err)
+	assert.EqualError(t, err, "nesting depth exceeds the limit of 1 at Subconfig.Subsubconfig")
+}
+
+func TestField_MaxFields(t *testing.T) {
+	type Subconfig struct {
+		Value string
+	}
+
+	type config struct {
+		Value     string
+		Subconfig Subconfig
+	}
+
+	c := config{}
+	ref := reflect.ValueOf(&c).Elem()
+
+	actual, err := getLimitedDefinitions(ref, definitionLimits{maxFields: 2})
+	require.NoError(t, err)
+	assert.Len(t, actual, 2)
+
+	_, err = getLimitedDefinitions(ref, definitionLimits{maxFields: 1})
+	require.Error(t, err)
+	assert.EqualError(t, err, "number of fields exceeds the limit of 1 at Subconfig.Value")
+}
+
+func TestGetDefinitions_InvalidWeight(t *testing.T) {
+	type config struct {
+		Value string `weight:"high"`
+	}
+
+	c := config{}
+
+	_, err := getDefinitions(reflect.ValueOf(c))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid weight for field Value")
+}
+
+func TestGetDefinitions_Enum(t *testing.T) {
+	type config struct {
+		Level string `enum:"debug, info,error"`
+	}
+
+	c := config{}
+
+	definitions, err := getDefinitions(reflect.ValueOf(c))
+	require.NoError(t, err)
+	require.Len(t, definitions, 1)
+	assert.Equal(t, []string{"debug", "info", "error"}, definitions[0].choices)
+}
+
+type decodableStruct struct {
+	Host string `flag:"host"`
+	Port int    `flag:"port"`
+}
+
+func (d *decodableStruct) Decode(value string) error {
+	d.Host = value
+
+	return nil
+}
+
+func TestGetDefinitions_AmbiguousDecoder(t *testing.T) {
+	type config struct {
+		Address decodableStruct
+	}
+
+	c := config{}
+
+	_, err := getDefinitions(reflect.ValueOf(&c).Elem())
+	require.Error(t, err)
+	assert.EqualError(t, err, "ambiguous field Address: nest.decodableStruct implements Decoder and has configuration tags, use the decode tag to choose")
+}
+
+func TestGetDefinitions_DecodeTag(t *testing.T) {
+	type config struct {
+		Address decodableStruct `decode:"true"`
+		Backend decodableStruct `decode:"false"`
+	}
+
+	c := config{}
+
+	definitions, err := getDefinitions(reflect.ValueOf(&c).Elem())
+	require.NoError(t, err)
+	require.Len(t, definitions, 3)
+	assert.Equal(t, "Address", definitions[0].key)
+	assert.Equal(t, "Backend.Host", definitions[1].key)
+	assert.Equal(t, "Backend.Port", definitions[2].key)
+}
+
+func TestField_IgnoredStruct(t *testing.T) {
+	type Subconfig struct {
+		Value string `default:"default"`
+	}
+
+	type config struct {
+		Subconfig `ignored:"true"`
+		Nested    Subconfig  `ignored:"true"`
+		Pointer   *Subconfig `ignored:"true"`
+	}
+
+	c := config{}
+
+	actual, err := getDefinitions(reflect.ValueOf(&c).Elem())
+	require.NoError(t, err)
+	assert.Empty(t, actual)
+	assert.Nil(t, c.Pointer)
+}
+
+func TestField_IgnoreFields(t *testing.T) {
+	type Subconfig struct {
+		Value  string
+		Debug  bool
+		Output string
+	}
+
+	type config struct {
+		Subconfig `ignore_fields:"Debug, Output"`
+		Nested    Subconfig `ignore_fields:"Value"`
+	}
+
+	c := config{}
+
+	actual, err := getDefinitions(reflect.ValueOf(&c).Elem())
+	require.NoError(t, err)
+	require.Len(t, actual, 3)
+	assert.Equal(t, "Subconfig.Value", actual[0].key)
+	assert.Equal(t, "Nested.Debug", actual[1].key)
+	assert.Equal(t, "Nested.Output", actual[2].key)
+}
+
+func TestField_SplitWordsCascade(t *testing.T) {
+	type Subconfig struct {
+		MaxIdle  int `env:"" flag:""`
+		MaxConns int `env:"" flag:"" split_words:"false"`
+	}
+
+	type config struct {
+		DatabaseConn Subconfig `split_words:"true"`
+	}
+
+	c := config{}
+
+	actual, err := getDefinitions(reflect.ValueOf(&c).Elem())
+	require.NoError(t, err)
+	require.Len(t, actual, 2)
+
+	assert.Equal(t, "database.conn.MaxIdle", actual[0].key)
+	assert.Equal(t, "database-conn-max-idle", actual[0].flagAlias)
+	assert.Equal(t, "DATABASE_CONN_MAX_IDLE", actual[0].envAlias)
+
+	assert.Equal(t, "database.conn.MaxConns", actual[1].key)
+	assert.Equal(t, "database-conn-maxConns", actual[1].flagAlias)
+	assert.Equal(t, "DATABASE_CONN_MAXCONNS", actual[1].envAlias)
+}
+
+func TestField_SplitWordsOption(t *testing.T) {
+	type config struct {
+		MaxIdle  int `env:"" flag:""`
+		MaxConns int `env:"" flag:"" split_words:"false"`
+	}
+
+	c := config{}
+
+	actual, err := getDefinitionsWithOptions(reflect.ValueOf(&c).Elem(), definitionOptions{splitWords: true})
+	require.NoError(t, err)
+	require.Len(t, actual, 2)
+
+	assert.Equal(t, "max-idle", actual[0].flagAlias)
+	assert.Equal(t, "MAX_IDLE", actual[0].envAlias)
+	assert.Equal(t, "maxConns", actual[1].flagAlias)
+	assert.Equal(t, "MAXCONNS", actual[1].envAlias)
+}
+
+func TestField_DefaultCase(t *testing.T) {
+	type Subconfig struct {
+		MaxIdle  int `env:"" flag:""`
+		MaxConns int `env:"" flag:"" split_words:"false"`
+	}
+
+	type config struct {
+		DatabaseConn Subconfig
+	}
+
+	c := config{}
+
+	actual, err := getDefinitionsWithOptions(reflect.ValueOf(&c).Elem(), definitionOptions{snakeEnv: true, spinalFlags: true})
+	require.NoError(t, err)
+	require.Len(t, actual, 2)
+
+	assert.Equal(t, "DatabaseConn.MaxIdle", actual[0].key)
+	assert.Equal(t, "database-conn-max-idle", actual[0].flagAlias)
+	assert.Equal(t, "DATABASE_CONN_MAX_IDLE", actual[0].envAlias)
+
+	assert.Equal(t, "database-conn-maxConns", actual[1].flagAlias)
+	assert.Equal(t, "DATABASE_CONN_MAXCONNS", actual[1].envAlias)
+}
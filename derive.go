@@ -0,0 +1,76 @@
+package nest
+
+import (
+	"reflect"
+)
+
+// Deriver is implemented by configuration structs that assemble derived values (eg. a DSN from host, port, user and password).
+//
+// Derive is called after every value is loaded. Child structs are derived before their parents.
+// Embedded structs follow the rules of method promotion: when the parent implements Deriver
+// (either by declaring Derive or by promoting it from the embedded struct), only the parent's Derive is called.
+type Deriver interface {
+	Derive() error
+}
+
+// deriverType is the reflection type of the Deriver interface.
+var deriverType = reflect.TypeOf((*Deriver)(nil)).Elem()
+
+// derive calls Derive on a struct and all of it's child structs implementing the Deriver interface.
+// Child structs are traversed the same way as during the definition of the fields.
+func derive(structRef reflect.Value) error {
+	return deriveStruct(structRef, true)
+}
+
+// deriveStruct derives the child structs of a struct and (if self is true) the struct itself.
+func deriveStruct(structRef reflect.Value, self bool) error {
+	structType := structRef.Type()
+	promoted := reflect.PtrTo(structType).Implements(deriverType)
+
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		field := structRef.Field(i)
+
+		// Ignore unexported field (unless it's an embedded struct, because it's exported fields are promoted)
+		if isExported(structField.Name) == false && !isEmbeddedStruct(structField) {
+			continue
+		}
+
+		// Manually ignored field
+		if value, ok := structField.Tag.Lookup(TagIgnored); ok && isTrue(value) {
+			continue
+		}
+
+		for field.Kind() == reflect.Ptr && !field.IsNil() {
+			field = field.Elem()
+		}
+
+		if field.Kind() != reflect.Struct {
+			continue
+		}
+
+		// Structs decoding themselves are leaves, unless the decode tag says otherwise
+		decodeStruct := canDecode(field)
+		if value, ok := structField.Tag.Lookup(TagDecode); ok {
+			decodeStruct = decodeStruct && isTrue(value)
+		}
+
+		if decodeStruct {
+			continue
+		}
+
+		// The Derive method of embedded structs is covered by the parent's
+		err := deriveStruct(field, !(structField.Anonymous && promoted))
+		if err != nil {
+			return err
+		}
+	}
+
+	if self && structRef.CanAddr() && structRef.Addr().CanInterface() {
+		if d, ok := structRef.Addr().Interface().(Deriver); ok {
+			return d.Derive()
+		}
+	}
+
+	return nil
+}
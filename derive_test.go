@@ -0,0 +1,134 @@
+package nest_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type DatabaseConfig struct {
+	Host string `default:"localhost"`
+	Port int    `default:"5432"`
+
+	Addr string `ignored:"true"`
+}
+
+func (c *DatabaseConfig) Derive() error {
+	c.Addr = fmt.Sprintf("%s:%d", c.Host, c.Port)
+
+	return nil
+}
+
+type DerivedConfig struct {
+	Database *DatabaseConfig
+	Name     string `default:"db"`
+
+	DSN string `ignored:"true"`
+}
+
+func (c *DerivedConfig) Derive() error {
+	c.DSN = fmt.Sprintf("postgres://%s/%s", c.Database.Addr, c.Name)
+
+	return nil
+}
+
+type InvalidDerivedConfig struct {
+	Value string
+}
+
+func (c *InvalidDerivedConfig) Derive() error {
+	return errors.New("invalid config")
+}
+
+func TestConfigurator_Load_Derive(t *testing.T) {
+	actual := DerivedConfig{}
+
+	configurator := nest.NewConfigurator()
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:5432", actual.Database.Addr)
+	assert.Equal(t, "postgres://localhost:5432/db", actual.DSN)
+}
+
+func TestConfigurator_Load_DeriveError(t *testing.T) {
+	actual := InvalidDerivedConfig{}
+
+	configurator := nest.NewConfigurator()
+
+	err := configurator.Load(&actual)
+	require.Error(t, err)
+	assert.EqualError(t, err, "invalid config")
+}
+
+type derivedBase struct {
+	Database DatabaseConfig
+}
+
+func TestConfigurator_Load_DeriveUnexportedEmbedded(t *testing.T) {
+	type config struct {
+		derivedBase
+	}
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:5432", actual.Database.Addr)
+}
+
+// DecodableDatabaseConfig is a DatabaseConfig which can also be decoded from a "host:port" string.
+type DecodableDatabaseConfig struct {
+	DatabaseConfig
+}
+
+func (c *DecodableDatabaseConfig) Decode(value string) error {
+	c.Host = value
+
+	return nil
+}
+
+func TestConfigurator_Load_DeriveDecoderStruct(t *testing.T) {
+	type config struct {
+		Database DecodableDatabaseConfig `decode:"false"`
+	}
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:5432", actual.Database.Addr)
+}
+
+// CountingDeriver counts the calls of Derive.
+type CountingDeriver struct {
+	Derived int `ignored:"true"`
+}
+
+func (c *CountingDeriver) Derive() error {
+	c.Derived++
+
+	return nil
+}
+
+func TestConfigurator_Load_DerivePromoted(t *testing.T) {
+	type config struct {
+		CountingDeriver
+	}
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, 1, actual.Derived)
+}
@@ -0,0 +1,132 @@
+package nest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// SetEnvFile sets a file in dotenv format (eg. .env) whose variables are added to the environment during Load.
+//
+// Variables of the real environment take precedence over the ones in the file. It's not an error if the file is missing.
+//
+// The file consists of NAME=value lines (optionally prefixed with export), empty lines and comments starting with #.
+// Values can be surrounded with quotes: escape sequences are interpreted and line breaks are allowed in double quoted
+// values, single quoted values are kept as is. Unquoted values end at a comment (" #").
+func (c *Configurator) SetEnvFile(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.envFile = path
+}
+
+// mergeEnvFile adds the variables of a dotenv file to an environment snapshot (without overwriting existing ones).
+func mergeEnvFile(environ map[string]string, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	vars, err := parseEnvFile(data)
+	if err != nil {
+		return fmt.Errorf("invalid env file %s: %v", path, err)
+	}
+
+	for name, value := range vars {
+		if _, ok := environ[name]; !ok {
+			environ[name] = value
+		}
+	}
+
+	return nil
+}
+
+// parseEnvFile parses the content of a dotenv file.
+func parseEnvFile(data []byte) (map[string]string, error) {
+	vars := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		i := strings.Index(line, "=")
+		if i < 1 {
+			return nil, fmt.Errorf("line %d: expected NAME=value", lineNumber)
+		}
+
+		name := strings.TrimSpace(line[:i])
+		if strings.ContainsAny(name, " \t\"'") {
+			return nil, fmt.Errorf("line %d: invalid variable name %q", lineNumber, name)
+		}
+
+		value := strings.TrimSpace(line[i+1:])
+		start := lineNumber
+
+		switch {
+		case value == "":
+
+		case value[0] == '"' || value[0] == '\'':
+			quote := value[:1]
+
+			// Double quoted values may span multiple lines
+			for quote == `"` && !hasClosingQuote(value) && scanner.Scan() {
+				lineNumber++
+				value += "\n" + scanner.Text()
+			}
+
+			// Only a comment may follow the closing quote
+			end := strings.LastIndex(value, quote)
+			if end < 1 {
+				return nil, fmt.Errorf("line %d: unterminated quoted value", start)
+			}
+
+			if rest := strings.TrimSpace(value[end+1:]); rest != "" && rest[0] != '#' {
+				return nil, fmt.Errorf("line %d: unexpected characters after quoted value", start)
+			}
+
+			value = unquoteValue(value[:end+1])
+
+		default:
+			if j := strings.Index(value, " #"); j > -1 {
+				value = strings.TrimSpace(value[:j])
+			}
+		}
+
+		vars[name] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
+// hasClosingQuote checks whether a double quoted value is terminated by an unescaped quote.
+func hasClosingQuote(value string) bool {
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+
+		case '"':
+			return true
+		}
+	}
+
+	return false
+}
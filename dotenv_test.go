@@ -0,0 +1,113 @@
+package nest_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurator_SetEnvFile(t *testing.T) {
+	path, cleanup := newConfigFile(t, ".env", `
+# Database connection
+export DB_HOST=db.example.com
+DB_PORT = 5432 # default port
+DB_USER='admin # not a comment'
+DB_PASSWORD="p\"ss"
+TLS_KEY="-----BEGIN KEY-----
+abc
+-----END KEY-----"
+EMPTY=
+HOST=file.example.com
+`)
+	defer cleanup()
+
+	type config struct {
+		DbHost     string `env:"" split_words:"true"`
+		DbPort     int    `env:"" split_words:"true"`
+		DbUser     string `env:"" split_words:"true"`
+		DbPassword string `env:"" split_words:"true"`
+		TlsKey     string `env:"" split_words:"true"`
+		Empty      string `env:"" default:"default"`
+		Host       string `env:""`
+	}
+
+	os.Clearenv()
+	os.Setenv("HOST", "env.example.com")
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.SetEnvFile(path)
+
+	report, err := configurator.LoadWithReport(&actual)
+	require.NoError(t, err)
+
+	assert.Equal(t, config{
+		DbHost:     "db.example.com",
+		DbPort:     5432,
+		DbUser:     "admin # not a comment",
+		DbPassword: `p"ss`,
+		TlsKey:     "-----BEGIN KEY-----\nabc\n-----END KEY-----",
+		Empty:      "default",
+		Host:       "env.example.com",
+	}, actual)
+
+	assert.Equal(t, nest.OriginEnv, report.Field("DbHost").Origin)
+	assert.Equal(t, "db.example.com", report.Env["DB_HOST"])
+
+	// The file is not loaded into the process environment
+	_, ok := os.LookupEnv("DB_HOST")
+	assert.False(t, ok)
+
+	os.Clearenv()
+}
+
+func TestConfigurator_SetEnvFile_Missing(t *testing.T) {
+	type config struct {
+		Host string `env:"" default:"localhost"`
+	}
+
+	os.Clearenv()
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.SetEnvFile(filepath.Join(os.TempDir(), "nest-missing", ".env"))
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", actual.Host)
+}
+
+func TestConfigurator_SetEnvFile_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"missing separator":  "HOST\n",
+		"invalid name":       "MY HOST=localhost\n",
+		"unterminated quote": "HOST=\"localhost\n",
+		"trailing content":   "HOST='localhost' port\n",
+	}
+
+	type config struct {
+		Host string `env:""`
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path, cleanup := newConfigFile(t, ".env", content)
+			defer cleanup()
+
+			configurator := nest.NewConfigurator()
+			configurator.SetArgs([]string{"app"})
+			configurator.SetEnvFile(path)
+
+			err := configurator.Load(&config{})
+			assert.Error(t, err)
+		})
+	}
+}
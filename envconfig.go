@@ -0,0 +1,15 @@
+package nest
+
+// Process populates a struct from environment variables the same way kelseyhightower/envconfig does.
+//
+// It can be used as a drop-in replacement of envconfig.Process while migrating to nest.
+func Process(prefix string, spec interface{}) error {
+	configurator := NewConfigurator()
+	configurator.CompatEnvconfig()
+	configurator.SetEnvPrefix(prefix)
+
+	// Command line arguments are not processed
+	configurator.SetArgs([]string{""})
+
+	return configurator.Load(spec)
+}
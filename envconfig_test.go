@@ -0,0 +1,75 @@
+package nest_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcess(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("MYAPP_DEBUG", "true")
+	os.Setenv("MYAPP_MAX_CONNS", "10")
+	os.Setenv("MYAPP_USER", "admin")
+	os.Setenv("MYAPP_DATABASE_HOST", "db.example.com")
+	os.Setenv("MYAPP_IGNORED", "value")
+
+	type database struct {
+		Host string
+	}
+
+	type config struct {
+		Debug    bool
+		MaxConns int    `split_words:"true"`
+		Username string `envconfig:"user"`
+		Port     int    `default:"8080"`
+		Database database
+		Ignored  string `ignored:"true"`
+	}
+
+	c := config{}
+
+	err := nest.Process("myapp", &c)
+	require.NoError(t, err)
+
+	expected := config{
+		Debug:    true,
+		MaxConns: 10,
+		Username: "admin",
+		Port:     8080,
+		Database: database{
+			Host: "db.example.com",
+		},
+	}
+	assert.Equal(t, expected, c)
+
+	os.Clearenv()
+}
+
+func TestProcess_Required(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Value string `required:"true"`
+	}
+
+	err := nest.Process("myapp", &config{})
+	assert.EqualError(t, err, "required field Value missing value")
+}
+
+func TestConfigurator_CompatEnvconfig_Usage(t *testing.T) {
+	type config struct {
+		Value string `desc:"My value"`
+	}
+
+	configurator := nest.NewConfigurator()
+	configurator.CompatEnvconfig()
+
+	schema, err := configurator.Parse(&config{})
+	require.NoError(t, err)
+	assert.Equal(t, "VALUE", schema.Field("Value").Env)
+	assert.Equal(t, "My value", schema.Field("Value").Usage)
+}
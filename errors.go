@@ -0,0 +1,180 @@
+package nest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// Error categories.
+//
+// Errors returned by the package can be matched against their category using Is (or errors.Is in Go 1.13 and later).
+var (
+	// ErrInvalidTarget is the category of errors caused by passing an unsupported value to Load.
+	ErrInvalidTarget = errors.New("invalid configuration target")
+
+	// ErrInfoRequested is the category of errors signaling that the user requested information (eg. help)
+	// instead of running the application.
+	ErrInfoRequested = errors.New("information requested")
+
+	// ErrLoadTimeout is the category of errors caused by exceeding the time limit set by SetLoadTimeout.
+	// The actual error is a *LoadTimeoutError.
+	ErrLoadTimeout = errors.New("configuration loading timed out")
+)
+
+var (
+	// ErrNotStructPointer is returned when value passed to config.Load() is not a pointer to a struct.
+	ErrNotStructPointer error = &categoryError{"value passed is not a struct pointer", []error{ErrInvalidTarget}}
+
+	// ErrNotStruct is returned when value passed to config.Load() is not a struct.
+	ErrNotStruct error = &categoryError{"value passed is not a struct", []error{ErrInvalidTarget}}
+
+	// ErrFlagHelp is returned when the commandline arguments include -h or --help.
+	// Application should exit without an error as pflag handles outputting the manual.
+	ErrFlagHelp error = &categoryError{pflag.ErrHelp.Error(), []error{ErrInfoRequested, pflag.ErrHelp}}
+
+	// ErrFlagVersion is returned when the commandline arguments include --version (and a version is set).
+	ErrFlagVersion error = &categoryError{"version requested", []error{ErrInfoRequested}}
+
+	// ErrConfigValid is returned when the commandline arguments include --validate-config (if enabled)
+	// and the configuration is valid.
+	ErrConfigValid error = &categoryError{"configuration validation requested", []error{ErrInfoRequested}}
+)
+
+// LoadTimeoutError is returned when loading the configuration exceeds the time limit set by SetLoadTimeout.
+type LoadTimeoutError struct {
+	Timeout time.Duration
+
+	// Unresolved lists the keys of the fields still being looked up in the sources when the time ran out.
+	Unresolved []string
+}
+
+// Error implements the error interface.
+func (e *LoadTimeoutError) Error() string {
+	if len(e.Unresolved) == 0 {
+		return fmt.Sprintf("configuration loading timed out after %s", e.Timeout)
+	}
+
+	return fmt.Sprintf("configuration loading timed out after %s, unresolved fields: %s", e.Timeout, strings.Join(e.Unresolved, ", "))
+}
+
+// Is reports whether the error belongs to the target category.
+func (e *LoadTimeoutError) Is(target error) bool {
+	return target == ErrLoadTimeout || target == context.DeadlineExceeded
+}
+
+// categoryError is a sentinel error belonging to one or more categories.
+type categoryError struct {
+	msg        string
+	categories []error
+}
+
+// Error implements the error interface.
+func (e *categoryError) Error() string {
+	return e.msg
+}
+
+// Is reports whether the error belongs to the target category.
+func (e *categoryError) Is(target error) bool {
+	for _, category := range e.categories {
+		if category == target {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Errors is a list of errors occurred while loading the configuration.
+type Errors []error
+
+// Error implements the error interface.
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Is reports whether any of the errors matches the target.
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Is reports whether an error (or any error it wraps) matches the target error or category.
+//
+// It follows the semantics of errors.Is, but works with Go versions before 1.13 as well.
+func Is(err, target error) bool {
+	for err != nil {
+		if err == target {
+			return true
+		}
+
+		if e, ok := err.(interface {
+			Is(error) bool
+		}); ok && e.Is(target) {
+			return true
+		}
+
+		e, ok := err.(interface {
+			Unwrap() error
+		})
+		if !ok {
+			return false
+		}
+
+		err = e.Unwrap()
+	}
+
+	return false
+}
+
+// As finds the first error in the chain that matches the type target points to and if so, sets target to that error.
+//
+// It follows the semantics of errors.As, but works with Go versions before 1.13 as well.
+func As(err error, target interface{}) bool {
+	val := reflect.ValueOf(target)
+	if target == nil || val.Kind() != reflect.Ptr || val.IsNil() {
+		panic("nest: target must be a non-nil pointer")
+	}
+
+	typ := val.Type().Elem()
+
+	for err != nil {
+		if reflect.TypeOf(err).AssignableTo(typ) {
+			val.Elem().Set(reflect.ValueOf(err))
+
+			return true
+		}
+
+		if e, ok := err.(interface {
+			As(interface{}) bool
+		}); ok && e.As(target) {
+			return true
+		}
+
+		e, ok := err.(interface {
+			Unwrap() error
+		})
+		if !ok {
+			return false
+		}
+
+		err = e.Unwrap()
+	}
+
+	return false
+}
@@ -0,0 +1,64 @@
+package nest_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+// wrappedError wraps another error.
+type wrappedError struct {
+	err error
+}
+
+func (e *wrappedError) Error() string {
+	return fmt.Sprintf("wrapped: %v", e.err)
+}
+
+func (e *wrappedError) Unwrap() error {
+	return e.err
+}
+
+func TestIs(t *testing.T) {
+	tests := map[string]struct {
+		err    error
+		target error
+		is     bool
+	}{
+		"identity":               {nest.ErrNotStruct, nest.ErrNotStruct, true},
+		"not_struct_category":    {nest.ErrNotStruct, nest.ErrInvalidTarget, true},
+		"not_pointer_category":   {nest.ErrNotStructPointer, nest.ErrInvalidTarget, true},
+		"help_category":          {nest.ErrFlagHelp, nest.ErrInfoRequested, true},
+		"help_pflag":             {nest.ErrFlagHelp, pflag.ErrHelp, true},
+		"help_not_invalid":       {nest.ErrFlagHelp, nest.ErrInvalidTarget, false},
+		"not_struct_not_pointer": {nest.ErrNotStruct, nest.ErrNotStructPointer, false},
+		"wrapped":                {&wrappedError{nest.ErrNotStruct}, nest.ErrInvalidTarget, true},
+		"errors":                 {nest.Errors{errors.New("error"), nest.ErrFlagHelp}, nest.ErrInfoRequested, true},
+		"other":                  {errors.New("error"), nest.ErrInvalidTarget, false},
+		"nil":                    {nil, nest.ErrInvalidTarget, false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.is, nest.Is(test.err, test.target))
+		})
+	}
+}
+
+func TestAs(t *testing.T) {
+	var errs nest.Errors
+
+	err := &wrappedError{nest.Errors{nest.ErrNotStruct}}
+
+	if assert.True(t, nest.As(err, &errs)) {
+		assert.Equal(t, nest.Errors{nest.ErrNotStruct}, errs)
+	}
+
+	var wrapped *wrappedError
+
+	assert.False(t, nest.As(nest.ErrNotStruct, &wrapped))
+}
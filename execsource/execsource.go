@@ -0,0 +1,111 @@
+// Package execsource implements a source delegating lookups to an external program.
+//
+// It lets organizations provide proprietary configuration backends as standalone binaries
+// instead of compiling them into every service.
+//
+// The program is started for every lookup and speaks JSON over it's standard streams:
+// it receives a request on the standard input and writes a response to the standard output.
+//
+//	request:  {"key": "Database.Host"}
+//	response: {"value": "db.example.com", "found": true}
+//
+// Missing keys are reported with "found": false, failures with an error message ({"error": "backend unavailable"}).
+// Exiting with a non-zero status is a failure as well (the standard error output is included in the error).
+package execsource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Request is sent to the program on the standard input.
+type Request struct {
+	Key string `json:"key"`
+}
+
+// Response is read from the standard output of the program.
+type Response struct {
+	Value string `json:"value"`
+	Found bool   `json:"found"`
+	Error string `json:"error,omitempty"`
+}
+
+// Source looks up values by running an external program.
+type Source struct {
+	path string
+	args []string
+	env  []string
+}
+
+// NewSource returns a new Source running the program at path with the given arguments.
+func NewSource(path string, args ...string) *Source {
+	return &Source{
+		path: path,
+		args: args,
+	}
+}
+
+// SetEnv sets the environment of the program (in key=value form).
+// By default the program inherits the environment of the current process.
+func (s *Source) SetEnv(env []string) {
+	s.env = env
+}
+
+// CheckSource implements the nest.SourceChecker interface.
+// It reports an error if the program cannot be found or is not executable.
+func (s *Source) CheckSource(ctx context.Context) error {
+	_, err := exec.LookPath(s.path)
+
+	return err
+}
+
+// Lookup implements the nest.Source interface.
+func (s *Source) Lookup(key string) (string, bool, error) {
+	return s.LookupContext(context.Background(), key)
+}
+
+// LookupContext implements the nest.ContextSource interface.
+// The program is killed when the context is cancelled.
+func (s *Source) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	request, err := json.Marshal(Request{Key: key})
+	if err != nil {
+		return "", false, err
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, s.path, s.args...)
+	cmd.Env = s.env
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", false, ctx.Err()
+		}
+
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", false, fmt.Errorf("source program %s failed: %v: %s", s.path, err, message)
+		}
+
+		return "", false, fmt.Errorf("source program %s failed: %v", s.path, err)
+	}
+
+	var response Response
+
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return "", false, fmt.Errorf("invalid response from source program %s: %v", s.path, err)
+	}
+
+	if response.Error != "" {
+		return "", false, errors.New(response.Error)
+	}
+
+	return response.Value, response.Found, nil
+}
@@ -0,0 +1,110 @@
+package execsource_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/goph/nest/execsource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain lets the test binary act as the source program when EXECSOURCE_TEST_PROGRAM is set.
+func TestMain(m *testing.M) {
+	if os.Getenv("EXECSOURCE_TEST_PROGRAM") == "1" {
+		program()
+
+		return
+	}
+
+	os.Exit(m.Run())
+}
+
+// program implements the source protocol backed by a map.
+func program() {
+	var request execsource.Request
+
+	if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	values := map[string]string{
+		"Database.Host": "db.example.com",
+	}
+
+	var response execsource.Response
+
+	switch request.Key {
+	case "Broken":
+		response.Error = "backend unavailable"
+
+	case "Crash":
+		fmt.Fprintln(os.Stderr, "segmentation fault")
+		os.Exit(2)
+
+	case "Garbage":
+		fmt.Print("garbage")
+
+		return
+
+	default:
+		response.Value, response.Found = values[request.Key]
+	}
+
+	json.NewEncoder(os.Stdout).Encode(response)
+}
+
+func newSource(t *testing.T) *execsource.Source {
+	executable, err := os.Executable()
+	require.NoError(t, err)
+
+	source := execsource.NewSource(executable)
+	source.SetEnv([]string{"EXECSOURCE_TEST_PROGRAM=1"})
+
+	return source
+}
+
+func TestSource(t *testing.T) {
+	type config struct {
+		Database struct {
+			Host string
+			Port int `default:"3306"`
+		}
+	}
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.AddSource(newSource(t))
+
+	err := configurator.Load(&c)
+	require.NoError(t, err)
+	assert.Equal(t, "db.example.com", c.Database.Host)
+	assert.Equal(t, 3306, c.Database.Port)
+}
+
+func TestSource_Errors(t *testing.T) {
+	source := newSource(t)
+
+	_, _, err := source.Lookup("Broken")
+	assert.EqualError(t, err, "backend unavailable")
+
+	_, _, err = source.Lookup("Crash")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exit status 2: segmentation fault")
+
+	_, _, err = source.Lookup("Garbage")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid response from source program")
+}
+
+func TestSource_CheckSource(t *testing.T) {
+	assert.NoError(t, newSource(t).CheckSource(context.Background()))
+	assert.Error(t, execsource.NewSource("/nonexistent/program").CheckSource(context.Background()))
+}
@@ -0,0 +1,419 @@
+package nest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// FileFormat parses the content of a configuration file into a (possibly nested) map.
+//
+// Nested maps are flattened into configuration keys (eg. database.host),
+// slices are joined with commas.
+type FileFormat func(data []byte) (map[string]interface{}, error)
+
+// DocumentValidator validates a parsed configuration document (eg. against a schema) before values are looked up.
+// Errors should identify the offending paths in the document.
+type DocumentValidator func(doc map[string]interface{}) error
+
+var (
+	fileFormats = map[string]FileFormat{
+		".json":  parseJSON,
+		".jsonc": parseJSON5,
+		".json5": parseJSON5,
+		".xml":   parseXML,
+		".yaml":  parseViperConfig("yaml"),
+		".yml":   parseViperConfig("yaml"),
+		".toml":  parseViperConfig("toml"),
+	}
+	fileFormatsMu sync.RWMutex
+)
+
+// RegisterFileFormat makes a file format available for file sources based on the file extension (eg. ".jsonnet").
+// If RegisterFileFormat is called twice with the same extension, it panics.
+func RegisterFileFormat(ext string, format FileFormat) {
+	fileFormatsMu.Lock()
+	defer fileFormatsMu.Unlock()
+
+	if format == nil {
+		panic("nest: RegisterFileFormat format is nil")
+	}
+
+	ext = strings.ToLower(ext)
+
+	if _, dup := fileFormats[ext]; dup {
+		panic("nest: RegisterFileFormat called twice for extension " + ext)
+	}
+
+	fileFormats[ext] = format
+}
+
+// getFileFormat returns the format registered for the extension of a file.
+func getFileFormat(path string) (FileFormat, bool) {
+	fileFormatsMu.RLock()
+	defer fileFormatsMu.RUnlock()
+
+	format, ok := fileFormats[strings.ToLower(filepath.Ext(path))]
+
+	return format, ok
+}
+
+// FileSource looks up values from a configuration file.
+//
+// The format of the file is chosen based on it's extension.
+// JSON files (.json), comment-tolerant JSON files (.jsonc, .json5), YAML files (.yaml, .yml), TOML files (.toml)
+// and XML files (.xml) are supported out of the box,
+// other formats can be added using RegisterFileFormat.
+//
+// The file is read on the first lookup. Keys are case-insensitive,
+// the keys of nested objects are joined with the key delimiter (see SetKeyDelimiter).
+type FileSource struct {
+	path      string
+	validator DocumentValidator
+
+	delimiter         string
+	explicitDelimiter bool
+
+	values     map[string]string
+	structured map[string]interface{}
+	mu         sync.Mutex
+}
+
+// NewFileSource returns a new FileSource reading the file located at path.
+func NewFileSource(path string) *FileSource {
+	return &FileSource{
+		path:      path,
+		delimiter: ".",
+	}
+}
+
+// SetKeyDelimiter sets the delimiter joining the keys of nested objects (defaults to ".").
+//
+// Unless it's set explicitly, the configurator the source is registered with sets it to it's own key delimiter
+// (see Configurator.SetKeyDelimiter), so that nested values are found by the keys of nested structs.
+func (s *FileSource) SetKeyDelimiter(delimiter string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.setKeyDelimiter(delimiter)
+	s.explicitDelimiter = true
+}
+
+// useKeyDelimiter sets the delimiter of the keys unless it's set explicitly.
+func (s *FileSource) useKeyDelimiter(delimiter string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.explicitDelimiter {
+		s.setKeyDelimiter(delimiter)
+	}
+}
+
+// setKeyDelimiter sets the delimiter of the keys and discards values flattened with a different one.
+func (s *FileSource) setKeyDelimiter(delimiter string) {
+	if delimiter != s.delimiter {
+		s.values = nil
+		s.structured = nil
+	}
+
+	s.delimiter = delimiter
+}
+
+// SetValidator sets a validator checking the parsed document when the file is read.
+func (s *FileSource) SetValidator(validator DocumentValidator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.validator = validator
+}
+
+// Lookup implements the Source interface.
+//
+// Objects can be looked up as well, their entries are formatted as comma separated key=value pairs
+// (so that they can be loaded into map fields).
+func (s *FileSource) Lookup(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.read(); err != nil {
+		return "", false, err
+	}
+
+	value, ok := s.values[strings.ToLower(key)]
+
+	return value, ok, nil
+}
+
+// lookupStructured implements the structuredSource interface.
+func (s *FileSource) lookupStructured(key string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.read(); err != nil {
+		return nil, false
+	}
+
+	value, ok := s.structured[strings.ToLower(key)]
+
+	return value, ok
+}
+
+// read reads the file unless it's already read.
+func (s *FileSource) read() error {
+	if s.values != nil {
+		return nil
+	}
+
+	values, structured, err := readConfigFile(s.path, s.validator, s.delimiter)
+	if err != nil {
+		return err
+	}
+
+	s.values = values
+	s.structured = structured
+
+	return nil
+}
+
+// CheckSource implements the SourceChecker interface.
+// It reports an error if the file cannot be read, parsed or validated.
+func (s *FileSource) CheckSource(ctx context.Context) error {
+	s.mu.Lock()
+	validator := s.validator
+	delimiter := s.delimiter
+	s.mu.Unlock()
+
+	_, _, err := readConfigFile(s.path, validator, delimiter)
+
+	return err
+}
+
+// readConfigFile reads, validates and flattens a configuration file.
+// The entries of the objects are returned as well (see flattenFileValues).
+func readConfigFile(path string, validator DocumentValidator, delimiter string) (map[string]string, map[string]interface{}, error) {
+	format, ok := getFileFormat(path)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported config file format: %s", path)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raw, err := format(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+
+	if validator != nil {
+		if err := validator(raw); err != nil {
+			return nil, nil, fmt.Errorf("invalid config file %s: %v", path, err)
+		}
+	}
+
+	values := make(map[string]string)
+	structured := make(map[string]interface{})
+	flattenFileValues("", delimiter, raw, values, structured)
+
+	return values, structured, nil
+}
+
+// flattenFileValues flattens a nested map into lowercase keys joined with the delimiter.
+//
+// Objects are kept under their own key as well: their entries (with the original case of the keys)
+// are collected into structured and formatted as key=value pairs into values.
+// The elements of lists are collected into structured too.
+func flattenFileValues(prefix string, delimiter string, raw map[string]interface{}, values map[string]string, structured map[string]interface{}) {
+	for key, value := range raw {
+		key = prefix + strings.ToLower(key)
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			entries := make(map[string]string, len(nested))
+			for name, value := range nested {
+				if _, isObject := value.(map[string]interface{}); !isObject && value != nil {
+					entries[name] = formatFileValue(value)
+				}
+			}
+
+			structured[key] = entries
+			values[key] = formatValue(entries)
+
+			flattenFileValues(key+delimiter, delimiter, nested, values, structured)
+
+			continue
+		}
+
+		if value == nil {
+			continue
+		}
+
+		if items, ok := value.([]interface{}); ok {
+			elements := make([]string, len(items))
+			for i, item := range items {
+				elements[i] = formatFileValue(item)
+			}
+
+			structured[key] = elements
+		}
+
+		values[key] = formatFileValue(value)
+	}
+}
+
+// formatFileValue converts a parsed value to it's string representation.
+func formatFileValue(value interface{}) string {
+	if items, ok := value.([]interface{}); ok {
+		s := make([]string, len(items))
+
+		for i, item := range items {
+			s[i] = formatFileValue(item)
+		}
+
+		return strings.Join(s, ",")
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+// parseJSON parses a JSON document.
+// Numbers are preserved as written.
+func parseJSON(data []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var raw map[string]interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// parseJSON5 parses a JSON document with comments, trailing commas, single-quoted strings and unquoted keys.
+func parseJSON5(data []byte) (map[string]interface{}, error) {
+	normalized, err := normalizeJSON5(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseJSON(normalized)
+}
+
+// SetConfigFile sets the configuration file values are read from (eg. config.yaml).
+//
+// The format of the file is chosen based on it's extension (see FileSource).
+// Values of the file take precedence over defaults, but environment variables, flags and overrides take precedence
+// over the file. The file is consulted after the sources added by AddSource.
+// Unlike files found in the directories added by AddConfigPath, the file must exist.
+func (c *Configurator) SetConfigFile(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.configFile = path
+}
+
+// AddConfigPath adds a directory to search the configuration file in when no file is set by SetConfigFile.
+//
+// The file is named after the application (see SetName) with the extension of a supported format (eg. app.yaml).
+// Directories are searched in the order they were added, the first file found is used.
+// It's not an error if there is no configuration file in any of the directories.
+func (c *Configurator) AddConfigPath(dir string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.configPaths = append(c.configPaths, dir)
+}
+
+// getSources returns the sources added by AddSource followed by the configuration file (if any).
+func (c *Configurator) getSources() []prioritizedSource {
+	return c.withConfigFile(c.sources)
+}
+
+// withConfigFile returns a copy of the sources followed by the configuration file (if any),
+// file sources are set up to use the key delimiter of the configurator.
+func (c *Configurator) withConfigFile(sources []prioritizedSource) []prioritizedSource {
+	all := make([]prioritizedSource, len(sources), len(sources)+1)
+	copy(all, sources)
+
+	if path, ok := c.findConfigFile(); ok {
+		all = append(all, prioritizedSource{Source: NewFileSource(path), priority: PrioritySource})
+	}
+
+	// Nested values of configuration files are looked up by the keys of nested structs
+	for _, source := range all {
+		if fileSource, ok := source.Source.(*FileSource); ok {
+			fileSource.useKeyDelimiter(c.getKeyDelimiter())
+		}
+	}
+
+	return all
+}
+
+// findConfigFile returns the path of the configuration file set by the --config flag (see SetConfigFlag),
+// SetConfigFile or the first one found in the directories added by AddConfigPath.
+func (c *Configurator) findConfigFile() (string, bool) {
+	if c.configFileArg != "" {
+		return c.configFileArg, true
+	}
+
+	if c.configFile != "" {
+		return c.configFile, true
+	}
+
+	if len(c.configPaths) == 0 {
+		return "", false
+	}
+
+	name := c.name
+	if name == "" && len(c.args) > 0 {
+		name = c.args[0]
+	}
+
+	name = filepath.Base(name)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+
+	fileFormatsMu.RLock()
+	exts := make([]string, 0, len(fileFormats))
+	for ext := range fileFormats {
+		exts = append(exts, ext)
+	}
+	fileFormatsMu.RUnlock()
+
+	sort.Strings(exts)
+
+	for _, dir := range c.configPaths {
+		for _, ext := range exts {
+			path := filepath.Join(dir, name+ext)
+
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// parseViperConfig returns a file format parsing documents with Viper (eg. YAML and TOML).
+func parseViperConfig(configType string) FileFormat {
+	return func(data []byte) (map[string]interface{}, error) {
+		v := viper.New()
+		v.SetConfigType(configType)
+
+		if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+
+		return v.AllSettings(), nil
+	}
+}
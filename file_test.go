@@ -0,0 +1,449 @@
+package nest_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newConfigFile(t *testing.T, name string, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "nest")
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, name)
+
+	err = ioutil.WriteFile(path, []byte(content), 0644)
+	require.NoError(t, err)
+
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestFileSource_JSON(t *testing.T) {
+	path, cleanup := newConfigFile(t, "config.json", `{"database": {"host": "db.example.com", "port": 3306}, "servers": ["a", "b"]}`)
+	defer cleanup()
+
+	type config struct {
+		Database struct {
+			Host string
+			Port int
+			User string `default:"root"`
+		}
+	}
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.AddSource(nest.NewFileSource(path))
+
+	err := configurator.Load(&c)
+	require.NoError(t, err)
+	assert.Equal(t, "db.example.com", c.Database.Host)
+	assert.Equal(t, 3306, c.Database.Port)
+	assert.Equal(t, "root", c.Database.User)
+}
+
+func TestFileSource_Array(t *testing.T) {
+	path, cleanup := newConfigFile(t, "config.json", `{"servers": ["a", "b"]}`)
+	defer cleanup()
+
+	value, ok, err := nest.NewFileSource(path).Lookup("Servers")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "a,b", value)
+}
+
+func TestFileSource_JSON5(t *testing.T) {
+	content := `// Hand-edited configuration
+{
+	/* connection settings */
+	database: {
+		host: 'db.example.com', // primary
+		port: 3306,
+	},
+	"debug": true,
+}`
+
+	for _, name := range []string{"config.json5", "config.jsonc"} {
+		path, cleanup := newConfigFile(t, name, content)
+		defer cleanup()
+
+		source := nest.NewFileSource(path)
+
+		value, ok, err := source.Lookup("Database.Host")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "db.example.com", value)
+
+		value, ok, err = source.Lookup("Debug")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "true", value)
+	}
+}
+
+func TestFileSource_ParseError(t *testing.T) {
+	path, cleanup := newConfigFile(t, "config.json", `{"database": `)
+	defer cleanup()
+
+	_, _, err := nest.NewFileSource(path).Lookup("database.host")
+	assert.EqualError(t, err, "failed to parse config file "+path+": unexpected EOF")
+}
+
+func TestFileSource_UnsupportedFormat(t *testing.T) {
+	_, _, err := nest.NewFileSource("config.ini").Lookup("key")
+	assert.EqualError(t, err, "unsupported config file format: config.ini")
+}
+
+func TestRegisterFileFormat(t *testing.T) {
+	// Evaluating Jsonnet is out of scope for the test, the format only has to produce JSON
+	nest.RegisterFileFormat(".testnet", func(data []byte) (map[string]interface{}, error) {
+		var raw map[string]interface{}
+		err := json.Unmarshal(data, &raw)
+
+		return raw, err
+	})
+
+	path, cleanup := newConfigFile(t, "config.testnet", `{"key": "value"}`)
+	defer cleanup()
+
+	value, ok, err := nest.NewFileSource(path).Lookup("key")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "value", value)
+
+	assert.Panics(t, func() { nest.RegisterFileFormat(".json", nil) })
+	assert.Panics(t, func() {
+		nest.RegisterFileFormat(".JSON", func(data []byte) (map[string]interface{}, error) { return nil, nil })
+	})
+}
+
+func TestFileSource_Validator(t *testing.T) {
+	path, cleanup := newConfigFile(t, "config.json", `{"database": {"port": "mysql"}}`)
+	defer cleanup()
+
+	source := nest.NewFileSource(path)
+	source.SetValidator(func(doc map[string]interface{}) error {
+		database := doc["database"].(map[string]interface{})
+		if _, ok := database["port"].(json.Number); !ok {
+			return errors.New("database.port: conflicting values int and string")
+		}
+
+		return nil
+	})
+
+	_, _, err := source.Lookup("database.port")
+	assert.EqualError(t, err, "invalid config file "+path+": database.port: conflicting values int and string")
+}
+
+func TestFileSource_XML(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<configuration>
+	<database host="db.example.com">
+		<port>3306</port>
+	</database>
+	<servers>
+		<server>a</server>
+		<server>b</server>
+	</servers>
+</configuration>`
+
+	path, cleanup := newConfigFile(t, "config.xml", content)
+	defer cleanup()
+
+	type config struct {
+		Database struct {
+			Host string
+			Port int
+		}
+	}
+
+	c := config{}
+
+	source := nest.NewFileSource(path)
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.AddSource(source)
+
+	err := configurator.Load(&c)
+	require.NoError(t, err)
+	assert.Equal(t, "db.example.com", c.Database.Host)
+	assert.Equal(t, 3306, c.Database.Port)
+
+	value, ok, err := source.Lookup("Servers.Server")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "a,b", value)
+}
+
+func TestConfigurator_SetConfigFile(t *testing.T) {
+	type config struct {
+		Host     string `env:"" default:"localhost"`
+		Port     int    `default:"80"`
+		Timeout  string `default:"1s"`
+		Database struct {
+			Name string
+		}
+	}
+
+	tests := map[string]string{
+		"config.yaml": "host: file.example.com\nport: 8080\ndatabase:\n  name: app\n",
+		"config.toml": "host = \"file.example.com\"\nport = 8080\n\n[database]\nname = \"app\"\n",
+		"config.json": `{"host": "file.example.com", "port": 8080, "database": {"name": "app"}}`,
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path, cleanup := newConfigFile(t, name, content)
+			defer cleanup()
+
+			os.Clearenv()
+			os.Setenv("HOST", "env.example.com")
+
+			actual := config{}
+
+			configurator := nest.NewConfigurator()
+			configurator.SetArgs([]string{"app"})
+			configurator.SetConfigFile(path)
+
+			report, err := configurator.LoadWithReport(&actual)
+			require.NoError(t, err)
+
+			assert.Equal(t, "env.example.com", actual.Host)
+			assert.Equal(t, 8080, actual.Port)
+			assert.Equal(t, "1s", actual.Timeout)
+			assert.Equal(t, "app", actual.Database.Name)
+			assert.Equal(t, nest.OriginSource, report.Field("Port").Origin)
+
+			os.Clearenv()
+		})
+	}
+}
+
+func TestConfigurator_SetConfigFile_Missing(t *testing.T) {
+	type config struct {
+		Port int
+	}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.SetConfigFile(filepath.Join(os.TempDir(), "nest-missing", "config.yaml"))
+
+	err := configurator.Load(&config{})
+	assert.Error(t, err)
+}
+
+func TestConfigurator_AddConfigPath(t *testing.T) {
+	type config struct {
+		Port int `default:"80"`
+	}
+
+	path, cleanup := newConfigFile(t, "app.yml", "port: 8080\n")
+	defer cleanup()
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"/usr/bin/app"})
+	configurator.AddConfigPath(filepath.Join(os.TempDir(), "nest-missing"))
+	configurator.AddConfigPath(filepath.Dir(path))
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, 8080, actual.Port)
+
+	// Missing files are ignored
+	actual = config{}
+
+	configurator = nest.NewConfigurator()
+	configurator.SetArgs([]string{"other"})
+	configurator.AddConfigPath(filepath.Dir(path))
+
+	err = configurator.Load(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, 80, actual.Port)
+}
+
+func TestConfigurator_SetConfigFlag(t *testing.T) {
+	type config struct {
+		Port int    `flag:"" default:"80"`
+		Host string `default:"localhost"`
+	}
+
+	flagPath, cleanup := newConfigFile(t, "flag.yaml", "host: flag.example.com\nport: 8080\n")
+	defer cleanup()
+
+	envPath, cleanup := newConfigFile(t, "env.yaml", "host: env.example.com\n")
+	defer cleanup()
+
+	filePath, cleanup := newConfigFile(t, "file.yaml", "host: file.example.com\n")
+	defer cleanup()
+
+	tests := map[string]struct {
+		args     []string
+		env      string
+		expected config
+	}{
+		"flag":              {[]string{"app", "--config", flagPath}, envPath, config{Port: 8080, Host: "flag.example.com"}},
+		"flag with value":   {[]string{"app", "--config=" + flagPath, "--port", "9090"}, "", config{Port: 9090, Host: "flag.example.com"}},
+		"env":               {[]string{"app"}, envPath, config{Port: 80, Host: "env.example.com"}},
+		"set by the app":    {[]string{"app"}, "", config{Port: 80, Host: "file.example.com"}},
+		"flag after values": {[]string{"app", "--port", "9090", "--config", flagPath}, "", config{Port: 9090, Host: "flag.example.com"}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			os.Clearenv()
+			if test.env != "" {
+				os.Setenv("APP_CONFIG", test.env)
+			}
+
+			actual := config{}
+
+			configurator := nest.NewConfigurator()
+			configurator.SetArgs(test.args)
+			configurator.SetEnvPrefix("app")
+			configurator.SetConfigFile(filePath)
+			configurator.SetConfigFlag(true)
+
+			err := configurator.Load(&actual)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+
+			os.Clearenv()
+		})
+	}
+}
+
+func TestConfigurator_SetConfigFlag_Disabled(t *testing.T) {
+	type config struct {
+		Host string `flag:""`
+	}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app", "--config", "config.yaml"})
+
+	err := configurator.Load(&config{})
+	assert.EqualError(t, err, "unknown flag: --config")
+}
+
+func TestConfigurator_SetConfigFile_Maps(t *testing.T) {
+	type config struct {
+		Labels  map[string]string
+		Limits  map[string]int
+		Headers map[string]string `separator:";"`
+		Nested  struct {
+			Value string
+		}
+	}
+
+	tests := map[string]string{
+		"config.yaml": "labels:\n  team: core\n  env: prod\nlimits:\n  cpu: 2\nheaders:\n  accept: text/html, application/json\nnested:\n  value: nested\n",
+		"config.json": `{"labels": {"team": "core", "env": "prod"}, "limits": {"cpu": 2}, "headers": {"accept": "text/html, application/json"}, "nested": {"value": "nested"}}`,
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path, cleanup := newConfigFile(t, name, content)
+			defer cleanup()
+
+			actual := config{}
+
+			configurator := nest.NewConfigurator()
+			configurator.SetArgs([]string{"app"})
+			configurator.SetConfigFile(path)
+
+			err := configurator.Load(&actual)
+			require.NoError(t, err)
+
+			assert.Equal(t, map[string]string{"team": "core", "env": "prod"}, actual.Labels)
+			assert.Equal(t, map[string]int{"cpu": 2}, actual.Limits)
+			assert.Equal(t, map[string]string{"accept": "text/html, application/json"}, actual.Headers)
+			assert.Equal(t, "nested", actual.Nested.Value)
+		})
+	}
+}
+
+func TestConfigurator_SetConfigFile_KeyDelimiter(t *testing.T) {
+	type config struct {
+		Database struct {
+			Host string
+		}
+	}
+
+	path, cleanup := newConfigFile(t, "config.json", `{"database": {"host": "db.example.com"}}`)
+	defer cleanup()
+
+	t.Run("config_file", func(t *testing.T) {
+		actual := config{}
+
+		configurator := nest.NewConfigurator()
+		configurator.SetArgs([]string{"app"})
+		configurator.SetKeyDelimiter("::")
+		configurator.SetConfigFile(path)
+
+		err := configurator.Load(&actual)
+		require.NoError(t, err)
+		assert.Equal(t, "db.example.com", actual.Database.Host)
+	})
+
+	t.Run("source", func(t *testing.T) {
+		actual := config{}
+
+		configurator := nest.NewConfigurator()
+		configurator.SetArgs([]string{"app"})
+		configurator.AddSource(nest.NewFileSource(path))
+		configurator.SetKeyDelimiter("::")
+
+		err := configurator.Load(&actual)
+		require.NoError(t, err)
+		assert.Equal(t, "db.example.com", actual.Database.Host)
+	})
+}
+
+func TestConfigurator_SetConfigFile_Lists(t *testing.T) {
+	type config struct {
+		Hosts  []string `separator:";"`
+		Names  []string
+		Ports  []int
+		Levels []string `enum:"debug,info"`
+	}
+
+	path, cleanup := newConfigFile(t, "config.yaml", "hosts: [x, y]\nnames: [\"Doe, John\", \"Roe, Jane\"]\nports: [80, 443]\nlevels: [debug]\n")
+	defer cleanup()
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.SetConfigFile(path)
+
+	report, err := configurator.LoadWithReport(&actual)
+	require.NoError(t, err)
+
+	assert.Equal(t, config{
+		Hosts:  []string{"x", "y"},
+		Names:  []string{"Doe, John", "Roe, Jane"},
+		Ports:  []int{80, 443},
+		Levels: []string{"debug"},
+	}, actual)
+	assert.Equal(t, "x;y", report.Field("Hosts").Value)
+
+	path, cleanup = newConfigFile(t, "config.yaml", "levels: [debug, trace]\n")
+	defer cleanup()
+
+	configurator = nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.SetConfigFile(path)
+
+	err = configurator.Load(&config{})
+	assert.EqualError(t, err, `invalid value "trace" for field Levels, must be one of: debug, info`)
+}
@@ -0,0 +1,394 @@
+package nest
+
+import (
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// boolValue is a bool flag value accepting the same spellings as bool fields (eg. --debug=yes).
+type boolValue bool
+
+func (b *boolValue) Set(s string) error {
+	v, err := parseBool(s)
+	if err != nil {
+		return err
+	}
+
+	*b = boolValue(v)
+
+	return nil
+}
+
+func (b *boolValue) Type() string {
+	return "bool"
+}
+
+func (b *boolValue) String() string {
+	return strconv.FormatBool(bool(*b))
+}
+
+// splitUnknownFlags separates arguments handled by a flag set from the rest (unknown flags and positional arguments).
+//
+// The first argument is treated as the program name and is always kept.
+// Values of unknown flags cannot be detected reliably: similarly to pflag,
+// the argument following an unknown flag is considered to be it's value unless it looks like a flag.
+func splitUnknownFlags(flags *pflag.FlagSet, args []string) ([]string, []string) {
+	if len(args) == 0 {
+		return args, nil
+	}
+
+	known := []string{args[0]}
+	var rest []string
+
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+
+		// Everything after the terminator is a positional argument
+		if arg == "--" {
+			rest = append(rest, args[i+1:]...)
+
+			break
+		}
+
+		// Positional argument
+		if len(arg) < 2 || arg[0] != '-' {
+			rest = append(rest, arg)
+
+			continue
+		}
+
+		var flag *pflag.Flag
+		name := strings.TrimLeft(arg, "-")
+		hasValue := strings.Contains(name, "=")
+		if hasValue {
+			name = name[:strings.Index(name, "=")]
+		}
+
+		if strings.HasPrefix(arg, "--") {
+			flag = flags.Lookup(name)
+		} else if len(name) == 1 {
+			flag = flags.ShorthandLookup(name)
+		}
+
+		// Help is handled by pflag even if it's not registered
+		if flag == nil && (name == "help" || name == "h") {
+			known = append(known, arg)
+
+			continue
+		}
+
+		next := i+1 < len(args) && (len(args[i+1]) == 0 || args[i+1][0] != '-')
+
+		if flag == nil {
+			rest = append(rest, arg)
+
+			if !hasValue && next {
+				i++
+				rest = append(rest, args[i])
+			}
+
+			continue
+		}
+
+		known = append(known, arg)
+
+		if !hasValue && flag.NoOptDefVal == "" && next {
+			i++
+			known = append(known, args[i])
+		}
+	}
+
+	return known, rest
+}
+
+// convertSlashFlags converts Windows style flags (/flag:value and /flag) to their GNU style equivalent.
+//
+// The first argument is treated as the program name and is never converted.
+func convertSlashFlags(flags *pflag.FlagSet, args []string) []string {
+	converted := make([]string, len(args))
+	copy(converted, args)
+
+	for i := 1; i < len(converted); i++ {
+		arg := converted[i]
+
+		if arg == "--" {
+			break
+		}
+
+		if len(arg) < 2 || arg[0] != '/' {
+			continue
+		}
+
+		name := arg[1:]
+		value := ""
+		if j := strings.Index(name, ":"); j > -1 {
+			name, value = name[:j], name[j:]
+		}
+
+		// /? is the customary way of asking for help
+		if name == "?" {
+			name = "help"
+		}
+
+		// Only convert registered flags, so that paths are left untouched
+		if flags.Lookup(name) == nil && name != "help" {
+			continue
+		}
+
+		if value != "" {
+			converted[i] = "--" + name + "=" + value[1:]
+		} else {
+			converted[i] = "--" + name
+		}
+	}
+
+	return converted
+}
+
+// isHelpRequested checks whether the arguments contain a help flag (--help, -h or /? with slash flags),
+// unless the application registered flags with the same name.
+func isHelpRequested(flags *pflag.FlagSet, args []string, slashFlags bool) bool {
+	for i := 1; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--":
+			return false
+
+		case arg == "--help" && flags.Lookup("help") == nil:
+			return true
+
+		case arg == "-h" && flags.ShorthandLookup("h") == nil && flags.Lookup("help") == nil:
+			return true
+
+		case slashFlags && (arg == "/?" || arg == "/help") && flags.Lookup("help") == nil:
+			return true
+		}
+	}
+
+	return false
+}
+
+// Names of the meta flags.
+const (
+	flagHelp           = "help"
+	flagVersion        = "version"
+	flagValidateConfig = "validate-config"
+	flagConfig         = "config"
+)
+
+// envConfig is the name of the environment variable setting the configuration file (without the prefix).
+const envConfig = "CONFIG"
+
+// flagAnnotationMeta marks the meta flags defined by the configurator (as opposed to the application).
+const flagAnnotationMeta = "nest_meta"
+
+// metaFlags are the flags controlling the loading process itself.
+type metaFlags struct {
+	help           bool
+	version        bool
+	validateConfig bool
+	configFile     string
+}
+
+// bootstrap extracts the meta flags from the arguments before the configuration is loaded.
+//
+// Every other flag is ignored, meta flags registered by the application are left to the application.
+func (c *Configurator) bootstrap(flags *pflag.FlagSet) metaFlags {
+	var meta metaFlags
+
+	if len(c.args) == 0 {
+		return meta
+	}
+
+	meta.help = c.earlyHelp && isHelpRequested(flags, c.args, c.slashFlags)
+
+	bootstrapFlags := pflag.NewFlagSet(c.args[0], pflag.ContinueOnError)
+	bootstrapFlags.ParseErrorsWhitelist.UnknownFlags = true
+	bootstrapFlags.SetOutput(ioutil.Discard)
+	bootstrapFlags.Usage = func() {}
+
+	// Help is handled above, the flag just keeps the parser going
+	bootstrapFlags.BoolP(flagHelp, "h", false, "")
+
+	if c.version != "" && !isAppFlag(flags, flagVersion) {
+		bootstrapFlags.BoolVar(&meta.version, flagVersion, false, "")
+	}
+
+	if c.validateConfigFlag && !isAppFlag(flags, flagValidateConfig) {
+		bootstrapFlags.BoolVar(&meta.validateConfig, flagValidateConfig, false, "")
+	}
+
+	if c.configFlag && !isAppFlag(flags, flagConfig) {
+		bootstrapFlags.StringVar(&meta.configFile, flagConfig, "", "")
+	}
+
+	// Errors are reported by the regular parser
+	bootstrapFlags.Parse(c.args[1:])
+
+	return meta
+}
+
+// isAppFlag checks whether a flag is registered by the application.
+func isAppFlag(flags *pflag.FlagSet, name string) bool {
+	flag := flags.Lookup(name)
+
+	return flag != nil && flag.Annotations[flagAnnotationMeta] == nil
+}
+
+// ParseArgs checks whether command line arguments are valid for a configuration struct without loading anything:
+// environment variables and sources are not consulted, neither the struct nor the flag set of the configurator is modified.
+//
+// Like the arguments set by SetArgs, the first argument is the program name.
+// Unknown flags, malformed values and values not among the allowed ones are reported,
+// values transformed by field hooks are only checked by Load.
+func (c *Configurator) ParseArgs(config interface{}, args []string) error {
+	elem, err := getTarget(config)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// The definition pass allocates nil pointers, so it works on a zero value of the struct
+	definitions, err := c.getDefinitions(reflect.New(elem.Type()).Elem())
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	flags := pflag.NewFlagSet(args[0], pflag.ContinueOnError)
+	flags.ParseErrorsWhitelist.UnknownFlags = c.ignoreUnknownFlags
+	flags.SetOutput(ioutil.Discard)
+	flags.Usage = func() {}
+
+	for _, def := range definitions {
+		if !def.hasFlag {
+			continue
+		}
+
+		flag := flags.VarPF(&checkValue{check: c.checkArg(def)}, c.mergeWithFlagPrefix(def.flagAlias), "", "")
+		if def.field.Kind() == reflect.Bool {
+			flag.NoOptDefVal = "true"
+		}
+
+		for _, alias := range def.keyAliases {
+			name := c.mergeWithFlagPrefix(aliasFlagName(alias, c.getKeyDelimiter()))
+			flags.AddFlag(&pflag.Flag{Name: name, Value: flag.Value, NoOptDefVal: flag.NoOptDefVal})
+		}
+	}
+
+	// Flags registered by the application are accepted with any value
+	if c.flags != nil {
+		c.flags.VisitAll(func(flag *pflag.Flag) {
+			_, bound := c.flagBindings[flag.Name]
+			if bound || flags.Lookup(flag.Name) != nil || flag.Annotations[flagAnnotationMeta] != nil {
+				return
+			}
+
+			flags.AddFlag(&pflag.Flag{
+				Name:        flag.Name,
+				Shorthand:   flag.Shorthand,
+				Value:       &checkValue{typ: flag.Value.Type()},
+				NoOptDefVal: flag.NoOptDefVal,
+			})
+		})
+	}
+
+	// Meta flags
+	if flags.Lookup(flagHelp) == nil {
+		flags.BoolP(flagHelp, "h", false, "")
+	}
+
+	if c.version != "" && flags.Lookup(flagVersion) == nil {
+		flags.Bool(flagVersion, false, "")
+	}
+
+	if c.validateConfigFlag && flags.Lookup(flagValidateConfig) == nil {
+		flags.Bool(flagValidateConfig, false, "")
+	}
+
+	if c.configFlag && flags.Lookup(flagConfig) == nil {
+		flags.String(flagConfig, "", "")
+	}
+
+	if c.slashFlags {
+		args = convertSlashFlags(flags, args)
+	}
+
+	if c.allowUnknownFlags {
+		args, _ = splitUnknownFlags(flags, args)
+	}
+
+	return flags.Parse(args[1:])
+}
+
+// checkArg returns a function checking whether a flag value can be loaded into a field.
+func (c *Configurator) checkArg(def fieldDefinition) func(value string) error {
+	return func(value string) error {
+		value = def.cleanValue(value)
+
+		// Encrypted values and values transformed by hooks cannot be checked without side effects
+		if len(c.fieldHooks) > 0 || isEncryptedValue(c.decryptors, value) {
+			return nil
+		}
+
+		elements := []string{value}
+		if def.separator != "" {
+			elements = splitSeparatedValue(value, def.separator)
+		}
+
+		if len(def.choices) > 0 {
+			for _, element := range elements {
+				if !isChoice(def.choices, element) {
+					return errors.New(c.translate(MsgInvalidChoice, element, def.key, strings.Join(def.choices, ", ")))
+				}
+			}
+		}
+
+		if def.template {
+			return nil
+		}
+
+		if c.coercer != nil {
+			if _, ok := c.coercer(def.field.Type(), value); ok {
+				return nil
+			}
+		}
+
+		return checkDefaultValue(def.field.Type(), value, def.separator)
+	}
+}
+
+// checkValue is a flag value which only checks the values set, without storing them.
+type checkValue struct {
+	check func(value string) error
+	typ   string
+}
+
+func (v *checkValue) Set(s string) error {
+	if v.check == nil {
+		return nil
+	}
+
+	return v.check(s)
+}
+
+func (v *checkValue) Type() string {
+	if v.typ == "" {
+		return "string"
+	}
+
+	return v.typ
+}
+
+func (v *checkValue) String() string {
+	return ""
+}
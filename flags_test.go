@@ -0,0 +1,104 @@
+package nest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitUnknownFlags(t *testing.T) {
+	flags := pflag.NewFlagSet("program", pflag.ContinueOnError)
+	flags.String("value", "", "")
+	flags.Bool("bool", false, "")
+
+	tests := map[string]struct {
+		args  []string
+		known []string
+		rest  []string
+	}{
+		"known": {
+			[]string{"program", "--value", "value", "--bool"},
+			[]string{"program", "--value", "value", "--bool"},
+			nil,
+		},
+		"unknown": {
+			[]string{"program", "--other", "other", "--value=value", "-x"},
+			[]string{"program", "--value=value"},
+			[]string{"--other", "other", "-x"},
+		},
+		"unknown_with_value": {
+			[]string{"program", "--other=other", "arg"},
+			[]string{"program"},
+			[]string{"--other=other", "arg"},
+		},
+		"bool_followed_by_argument": {
+			[]string{"program", "--bool", "arg"},
+			[]string{"program", "--bool"},
+			[]string{"arg"},
+		},
+		"terminator": {
+			[]string{"program", "--", "--value", "value"},
+			[]string{"program"},
+			[]string{"--value", "value"},
+		},
+		"help": {
+			[]string{"program", "--help"},
+			[]string{"program", "--help"},
+			nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			known, rest := splitUnknownFlags(flags, test.args)
+
+			assert.Equal(t, test.known, known)
+			assert.Equal(t, test.rest, rest)
+		})
+	}
+}
+
+func TestConvertSlashFlags(t *testing.T) {
+	flags := pflag.NewFlagSet("program", pflag.ContinueOnError)
+	flags.String("value", "", "")
+	flags.Bool("bool", false, "")
+
+	args := []string{"/program", "/value:C:\\value", "/bool", "/usr/bin", "/?", "--", "/value:value"}
+	expected := []string{"/program", "--value=C:\\value", "--bool", "/usr/bin", "--help", "--", "/value:value"}
+
+	assert.Equal(t, expected, convertSlashFlags(flags, args))
+}
+
+func TestIsHelpRequested(t *testing.T) {
+	flags := pflag.NewFlagSet("program", pflag.ContinueOnError)
+	flags.StringP("host", "H", "", "")
+
+	shorthandFlags := pflag.NewFlagSet("program", pflag.ContinueOnError)
+	shorthandFlags.StringP("host", "h", "", "")
+
+	assert.True(t, isHelpRequested(flags, []string{"program", "--host", "localhost", "--help"}, false))
+	assert.True(t, isHelpRequested(flags, []string{"program", "-h"}, false))
+	assert.True(t, isHelpRequested(flags, []string{"program", "/?"}, true))
+	assert.False(t, isHelpRequested(flags, []string{"program", "/?"}, false))
+	assert.False(t, isHelpRequested(flags, []string{"program", "--", "--help"}, false))
+	assert.False(t, isHelpRequested(flags, []string{"--help"}, false))
+	assert.False(t, isHelpRequested(shorthandFlags, []string{"program", "-h", "localhost"}, false))
+}
+
+func TestConfigurator_Outputs(t *testing.T) {
+	c := NewConfigurator()
+
+	assert.Equal(t, os.Stdout, c.out())
+	assert.Equal(t, os.Stderr, c.errOut())
+
+	c.SetOutput(os.Stderr)
+
+	assert.Equal(t, os.Stderr, c.out())
+	assert.Equal(t, os.Stderr, c.errOut())
+
+	c.SetErrorOutput(os.Stdout)
+
+	assert.Equal(t, os.Stdout, c.errOut())
+}
@@ -1,5 +1,14 @@
 package nest
 
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
 // c is a global Configurator instance following Viper's singleton principle.
 var c *Configurator
 
@@ -26,3 +35,193 @@ func SetArgs(args []string) {
 func Load(config interface{}) error {
 	return c.Load(config)
 }
+
+// LoadPrefix calls the function with the same name on the global configurator instance.
+func LoadPrefix(prefix string, config interface{}) error {
+	return c.LoadPrefix(prefix, config)
+}
+
+// LoadMap calls the function with the same name on the global configurator instance.
+func LoadMap(config map[string]interface{}) error {
+	return c.LoadMap(config)
+}
+
+// AddSource calls the function with the same name on the global configurator instance.
+func AddSource(source Source) {
+	c.AddSource(source)
+}
+
+// AddSourceWithPriority calls the function with the same name on the global configurator instance.
+func AddSourceWithPriority(source Source, priority int) {
+	c.AddSourceWithPriority(source, priority)
+}
+
+// SetEnvFile calls the function with the same name on the global configurator instance.
+func SetEnvFile(path string) {
+	c.SetEnvFile(path)
+}
+
+// SetConfigFile calls the function with the same name on the global configurator instance.
+func SetConfigFile(path string) {
+	c.SetConfigFile(path)
+}
+
+// AddConfigPath calls the function with the same name on the global configurator instance.
+func AddConfigPath(dir string) {
+	c.AddConfigPath(dir)
+}
+
+// SetDecryptor calls the function with the same name on the global configurator instance.
+func SetDecryptor(scheme string, decryptor Decryptor) {
+	c.SetDecryptor(scheme, decryptor)
+}
+
+// AddFieldHook calls the function with the same name on the global configurator instance.
+func AddFieldHook(hook FieldHook) {
+	c.AddFieldHook(hook)
+}
+
+// SetEnvPrefixes calls the function with the same name on the global configurator instance.
+func SetEnvPrefixes(prefix string, legacyPrefixes ...string) {
+	c.SetEnvPrefixes(prefix, legacyPrefixes...)
+}
+
+// LoadContext calls the function with the same name on the global configurator instance.
+func LoadContext(ctx context.Context, config interface{}) error {
+	return c.LoadContext(ctx, config)
+}
+
+// Usage calls the function with the same name on the global configurator instance.
+func Usage(config interface{}, w io.Writer) error {
+	return c.Usage(config, w)
+}
+
+// ParseArgs calls the function with the same name on the global configurator instance.
+func ParseArgs(config interface{}, args []string) error {
+	return c.ParseArgs(config, args)
+}
+
+// SetTranslator calls the function with the same name on the global configurator instance.
+func SetTranslator(translator Translator) {
+	c.SetTranslator(translator)
+}
+
+// SetColorMode calls the function with the same name on the global configurator instance.
+func SetColorMode(mode ColorMode) {
+	c.SetColorMode(mode)
+}
+
+// SetUsageOrder calls the function with the same name on the global configurator instance.
+func SetUsageOrder(order UsageOrder) {
+	c.SetUsageOrder(order)
+}
+
+// Flags calls the function with the same name on the global configurator instance.
+func Flags() *pflag.FlagSet {
+	return c.Flags()
+}
+
+// Parse calls the function with the same name on the global configurator instance.
+func Parse(config interface{}) (*Schema, error) {
+	return c.Parse(config)
+}
+
+// SetCoercer calls the function with the same name on the global configurator instance.
+func SetCoercer(coercer Coercer) {
+	c.SetCoercer(coercer)
+}
+
+// SetSplitWords calls the function with the same name on the global configurator instance.
+func SetSplitWords(splitWords bool) {
+	c.SetSplitWords(splitWords)
+}
+
+// SetEarlyHelp calls the function with the same name on the global configurator instance.
+func SetEarlyHelp(earlyHelp bool) {
+	c.SetEarlyHelp(earlyHelp)
+}
+
+// SetVersion calls the function with the same name on the global configurator instance.
+func SetVersion(version string) {
+	c.SetVersion(version)
+}
+
+// SetValidateConfigFlag calls the function with the same name on the global configurator instance.
+func SetValidateConfigFlag(validateConfigFlag bool) {
+	c.SetValidateConfigFlag(validateConfigFlag)
+}
+
+// SetConfigFlag calls the function with the same name on the global configurator instance.
+func SetConfigFlag(configFlag bool) {
+	c.SetConfigFlag(configFlag)
+}
+
+// SetStrictConflicts calls the function with the same name on the global configurator instance.
+func SetStrictConflicts(strictConflicts bool) {
+	c.SetStrictConflicts(strictConflicts)
+}
+
+// SetTrimSpace calls the function with the same name on the global configurator instance.
+func SetTrimSpace(trimSpace bool) {
+	c.SetTrimSpace(trimSpace)
+}
+
+// SetMode calls the function with the same name on the global configurator instance.
+func SetMode(mode string) {
+	c.SetMode(mode)
+}
+
+// DevMode calls the function with the same name on the global configurator instance.
+func DevMode() {
+	c.DevMode()
+}
+
+// SetLoadTimeout calls the function with the same name on the global configurator instance.
+func SetLoadTimeout(timeout time.Duration) {
+	c.SetLoadTimeout(timeout)
+}
+
+// SetErrorRenderer calls the function with the same name on the global configurator instance.
+func SetErrorRenderer(renderer ErrorRenderer) {
+	c.SetErrorRenderer(renderer)
+}
+
+// SetPrintErrors calls the function with the same name on the global configurator instance.
+func SetPrintErrors(printErrors bool) {
+	c.SetPrintErrors(printErrors)
+}
+
+// SetStrictTags calls the function with the same name on the global configurator instance.
+func SetStrictTags(strictTags bool, allowed ...string) {
+	c.SetStrictTags(strictTags, allowed...)
+}
+
+// SetMergeEmbedded calls the function with the same name on the global configurator instance.
+func SetMergeEmbedded(mergeEmbedded bool) {
+	c.SetMergeEmbedded(mergeEmbedded)
+}
+
+// SetDefaultCase calls the function with the same name on the global configurator instance.
+func SetDefaultCase(cases ...NameCase) {
+	c.SetDefaultCase(cases...)
+}
+
+// SetFlagPrefix calls the function with the same name on the global configurator instance.
+func SetFlagPrefix(prefix string) {
+	c.SetFlagPrefix(prefix)
+}
+
+// SetKeyDelimiter calls the function with the same name on the global configurator instance.
+func SetKeyDelimiter(delimiter string) {
+	c.SetKeyDelimiter(delimiter)
+}
+
+// LoadWithReport calls the function with the same name on the global configurator instance.
+func LoadWithReport(config interface{}) (*Report, error) {
+	return c.LoadWithReport(config)
+}
+
+// BindDefinitions calls the function with the same name on the global configurator instance.
+func BindDefinitions(v *viper.Viper, config interface{}) error {
+	return c.BindDefinitions(v, config)
+}
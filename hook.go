@@ -0,0 +1,65 @@
+package nest
+
+import (
+	"reflect"
+)
+
+// FieldInfo describes a configuration field.
+type FieldInfo struct {
+	// Key is the configuration key of the field (eg. "Database.Host").
+	Key string
+
+	// Type is the type of the field.
+	Type reflect.Type
+
+	// Flag is the name of the command line flag mapped to the field (if any).
+	Flag string
+
+	// Env is the name of the environment variable mapped to the field (if any).
+	Env string
+
+	// Secret reports whether the field holds sensitive data.
+	Secret bool
+}
+
+// FieldHook transforms the raw value of a field before it is processed.
+type FieldHook func(field FieldInfo, raw string) (string, error)
+
+// fieldInfo returns the public description of a field definition.
+func (c *Configurator) fieldInfo(def fieldDefinition) FieldInfo {
+	info := FieldInfo{
+		Key:    def.key,
+		Type:   def.field.Type(),
+		Secret: def.secret,
+	}
+
+	if def.hasFlag {
+		info.Flag = c.mergeWithFlagPrefix(def.flagAlias)
+	}
+
+	if def.hasEnv {
+		info.Env = c.mergeWithEnvPrefix(def.envAlias)
+	}
+
+	return info
+}
+
+// applyFieldHooks runs a value through the field hooks in order.
+func (c *Configurator) applyFieldHooks(def fieldDefinition, value string) (string, error) {
+	if len(c.fieldHooks) == 0 {
+		return value, nil
+	}
+
+	info := c.fieldInfo(def)
+
+	for _, hook := range c.fieldHooks {
+		var err error
+
+		value, err = hook(info, value)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return value, nil
+}
@@ -0,0 +1,80 @@
+package nest_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurator_Load_FieldHook(t *testing.T) {
+	type config struct {
+		Value string `env:"" flag:""`
+		Int   int    `default:" 1 "`
+	}
+
+	expected := config{
+		Value: "VALUE",
+		Int:   1,
+	}
+	actual := config{}
+
+	var infos []nest.FieldInfo
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"program"})
+	configurator.SetEnvPrefix("app")
+	configurator.AddFieldHook(func(field nest.FieldInfo, raw string) (string, error) {
+		infos = append(infos, field)
+
+		return strings.TrimSpace(raw), nil
+	})
+	configurator.AddFieldHook(func(field nest.FieldInfo, raw string) (string, error) {
+		return strings.ToUpper(raw), nil
+	})
+
+	os.Clearenv()
+	os.Setenv("APP_VALUE", " value\n")
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	expectedInfos := []nest.FieldInfo{
+		{
+			Key:  "Value",
+			Type: reflect.TypeOf(""),
+			Flag: "value",
+			Env:  "APP_VALUE",
+		},
+		{
+			Key:  "Int",
+			Type: reflect.TypeOf(0),
+		},
+	}
+	assert.Equal(t, expectedInfos, infos)
+
+	os.Clearenv()
+}
+
+func TestConfigurator_Load_FieldHookError(t *testing.T) {
+	type config struct {
+		Value string `default:"value"`
+	}
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.AddFieldHook(func(field nest.FieldInfo, raw string) (string, error) {
+		return "", errors.New("hook failed")
+	})
+
+	err := configurator.Load(&actual)
+	require.Error(t, err)
+	assert.EqualError(t, err, "hook failed")
+}
@@ -0,0 +1,171 @@
+// Package httpdebug provides an HTTP handler exposing the loaded configuration for debugging purposes.
+//
+// The handler can be mounted next to pprof:
+//
+//	handler := httpdebug.NewHandler()
+//	configurator.OnAfterLoad(handler.Record)
+//
+//	http.Handle("/debug/config", handler)
+package httpdebug
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goph/nest"
+)
+
+// DefaultHistorySize is the number of loads kept in the history by default.
+const DefaultHistorySize = 10
+
+// Load is a single configuration load recorded by the handler.
+type Load struct {
+	Time     time.Time          `json:"time"`
+	Duration time.Duration      `json:"duration"`
+	Fields   []nest.FieldReport `json:"fields"`
+	Warnings []string           `json:"warnings,omitempty"`
+
+	// Changed lists the keys of the fields whose value changed compared to the previous load.
+	Changed []string `json:"changed,omitempty"`
+}
+
+// Handler renders the loaded configuration (secret values are hidden), where the values come from
+// and the history of loads as HTML or JSON.
+type Handler struct {
+	historySize int
+	history     []Load
+
+	mu sync.RWMutex
+}
+
+// NewHandler returns a new Handler.
+func NewHandler() *Handler {
+	return &Handler{
+		historySize: DefaultHistorySize,
+	}
+}
+
+// SetHistorySize sets the number of loads kept in the history.
+func (h *Handler) SetHistorySize(size int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.historySize = size
+	h.truncate()
+}
+
+// Record adds a load to the history.
+// It's signature makes it usable as an after load hook.
+func (h *Handler) Record(config interface{}, report *nest.Report) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	load := Load{
+		Time:     time.Now(),
+		Duration: report.Duration,
+		Fields:   report.Fields,
+		Warnings: report.Warnings,
+	}
+
+	if len(h.history) > 0 {
+		load.Changed = changedKeys(h.history[len(h.history)-1].Fields, report.Fields)
+	}
+
+	h.history = append(h.history, load)
+	h.truncate()
+}
+
+// History returns the recorded loads (the latest is the last one).
+func (h *Handler) History() []Load {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	history := make([]Load, len(h.history))
+	copy(history, h.history)
+
+	return history
+}
+
+// ServeHTTP implements the http.Handler interface.
+//
+// JSON is rendered when the format query parameter is "json" or the client accepts JSON.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	history := h.History()
+
+	if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		json.NewEncoder(w).Encode(history)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	var current *Load
+	if len(history) > 0 {
+		current = &history[len(history)-1]
+	}
+
+	page.Execute(w, struct {
+		Current *Load
+		History []Load
+	}{
+		Current: current,
+		History: history,
+	})
+}
+
+// truncate removes the oldest loads exceeding the history size.
+func (h *Handler) truncate() {
+	if h.historySize > 0 && len(h.history) > h.historySize {
+		h.history = h.history[len(h.history)-h.historySize:]
+	}
+}
+
+// changedKeys returns the keys of the fields whose value changed.
+func changedKeys(previous []nest.FieldReport, current []nest.FieldReport) []string {
+	values := make(map[string]string, len(previous))
+	for _, field := range previous {
+		values[field.Key] = field.Value
+	}
+
+	var changed []string
+
+	for _, field := range current {
+		if value, ok := values[field.Key]; !ok || value != field.Value {
+			changed = append(changed, field.Key)
+		}
+	}
+
+	return changed
+}
+
+var page = template.Must(template.New("config").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<title>Configuration</title>
+</head>
+<body>
+<h1>Configuration</h1>
+{{ with .Current }}
+<table>
+<tr><th>Key</th><th>Value</th><th>Origin</th><th>Name</th></tr>
+{{ range .Fields }}<tr><td>{{ .Key }}</td><td>{{ .Value }}</td><td>{{ .Origin }}</td><td>{{ .Name }}</td></tr>
+{{ end }}</table>
+{{ range .Warnings }}<p>Warning: {{ . }}</p>
+{{ end }}{{ else }}
+<p>The configuration is not loaded yet.</p>
+{{ end }}
+<h2>History</h2>
+<table>
+<tr><th>Time</th><th>Duration</th><th>Changed</th></tr>
+{{ range .History }}<tr><td>{{ .Time.Format "2006-01-02T15:04:05Z07:00" }}</td><td>{{ .Duration }}</td><td>{{ range $i, $key := .Changed }}{{ if $i }}, {{ end }}{{ $key }}{{ end }}</td></tr>
+{{ end }}</table>
+</body>
+</html>
+`))
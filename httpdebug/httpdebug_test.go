@@ -0,0 +1,86 @@
+package httpdebug_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/goph/nest/httpdebug"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type config struct {
+	Host     string `flag:"host" default:"localhost"`
+	Password string `flag:"password" secret:"true"`
+}
+
+func load(t *testing.T, handler *httpdebug.Handler, args ...string) {
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs(append([]string{"app"}, args...))
+	configurator.OnAfterLoad(handler.Record)
+
+	err := configurator.Load(&config{})
+	require.NoError(t, err)
+}
+
+func TestHandler_JSON(t *testing.T) {
+	handler := httpdebug.NewHandler()
+
+	load(t, handler, "--password", "s3cr3t")
+	load(t, handler, "--host", "example.com", "--password", "s3cr3t")
+
+	req := httptest.NewRequest("GET", "/debug/config?format=json", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.NotContains(t, rec.Body.String(), "s3cr3t")
+
+	var history []httpdebug.Load
+	err := json.Unmarshal(rec.Body.Bytes(), &history)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+
+	assert.Equal(t, nest.FieldReport{Key: "Host", Origin: nest.OriginFlag, Name: "--host", Value: "example.com"}, history[1].Fields[0])
+	assert.Equal(t, "<hidden>", history[1].Fields[1].Value)
+	assert.Equal(t, []string{"Host"}, history[1].Changed)
+}
+
+func TestHandler_HTML(t *testing.T) {
+	handler := httpdebug.NewHandler()
+
+	req := httptest.NewRequest("GET", "/debug/config", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+	assert.Contains(t, rec.Body.String(), "The configuration is not loaded yet.")
+
+	load(t, handler, "--password", "s3cr3t")
+
+	rec = httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "<td>Host</td><td>localhost</td><td>default</td>")
+	assert.Contains(t, rec.Body.String(), "<td>Password</td><td>&lt;hidden&gt;</td><td>flag</td>")
+}
+
+func TestHandler_SetHistorySize(t *testing.T) {
+	handler := httpdebug.NewHandler()
+	handler.SetHistorySize(2)
+
+	load(t, handler)
+	load(t, handler, "--host", "a")
+	load(t, handler, "--host", "b")
+
+	history := handler.History()
+	require.Len(t, history, 2)
+	assert.Equal(t, "a", history[0].Fields[0].Value)
+	assert.Equal(t, "b", history[1].Fields[0].Value)
+}
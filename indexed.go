@@ -0,0 +1,150 @@
+package nest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// envCountSuffix is appended to the environment variable name of a slice of structs to get the number of elements.
+const envCountSuffix = "_COUNT"
+
+// isIndexedType checks whether a type is a slice of structs, which is loaded from indexed environment variables.
+func isIndexedType(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Slice {
+		return false
+	}
+
+	elem := typ.Elem()
+
+	return elem.Kind() == reflect.Struct && !implementsDecoder(elem) && !implementsDecoder(reflect.PtrTo(elem))
+}
+
+// applyIndexed loads a slice of structs from indexed environment variables:
+// the fields of the element at index i are read from NAME_i_FIELD (eg. SERVERS_0_HOST).
+//
+// The number of elements is read from NAME_COUNT, which also allows empty trailing elements.
+// Without it indexes are scanned until there is no variable for the next one.
+// It returns the name of the environment variable the elements were loaded from (or an empty string if there are none).
+// The variables read are recorded in the report.
+func (c *Configurator) applyIndexed(ctx context.Context, report *Report, def fieldDefinition, envName string) (string, error) {
+	count, name, err := c.getIndexedCount(report, def, envName)
+	if err != nil || name == "" {
+		return "", err
+	}
+
+	slice := reflect.MakeSlice(def.field.Type(), count, count)
+	delimiter := c.getKeyDelimiter()
+
+	for i := 0; i < count; i++ {
+		elem := slice.Index(i)
+		prefix := fmt.Sprintf("%s_%d_", envName, i)
+		keyPrefix := def.key + delimiter + strconv.Itoa(i) + delimiter
+
+		definitions, err := c.getDefinitions(elem)
+		if err != nil {
+			return "", err
+		}
+
+		for _, elemDef := range definitions {
+			elemName := prefix + c.getElementEnvName(elemDef)
+			elemDef.key = keyPrefix + elemDef.key
+
+			if elemDef.indexed {
+				if _, err := c.applyIndexed(ctx, report, elemDef, elemName); err != nil {
+					return "", err
+				}
+
+				continue
+			}
+
+			var value string
+
+			if actualName, ok := c.lookupEnv(elemName); ok {
+				value = c.getenv(actualName)
+				report.addEnv(actualName, value, def.secret || elemDef.secret)
+			} else if elemDef.hasDefault {
+				value = elemDef.defaultValue
+			} else if elemDef.required {
+				return "", errors.New(c.translate(MsgRequiredMissing, elemDef.key))
+			} else {
+				continue
+			}
+
+			value, err = c.prepareValue(elemDef, value)
+			if err != nil {
+				return "", err
+			}
+
+			if err := c.applyValue(ctx, elemDef, value); err != nil {
+				return "", err
+			}
+
+			if elemDef.setter != nil {
+				if err := elemDef.setter(); err != nil {
+					return "", fmt.Errorf("failed to set %s: %v", elemDef.key, err)
+				}
+			}
+		}
+	}
+
+	def.field.Set(slice)
+
+	return name, nil
+}
+
+// getIndexedCount returns the number of elements of a slice of structs loaded from indexed environment variables
+// and the name of the variable it was determined from (or an empty string if there are no elements).
+func (c *Configurator) getIndexedCount(report *Report, def fieldDefinition, envName string) (int, string, error) {
+	if countName, ok := c.lookupEnv(envName + envCountSuffix); ok {
+		value := c.getenv(countName)
+		report.addEnv(countName, value, false)
+
+		count, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || count < 0 {
+			return 0, "", fmt.Errorf("invalid %s for field %s: %q is not a valid number of elements", countName, def.key, value)
+		}
+
+		return count, countName, nil
+	}
+
+	count := 0
+	for c.hasEnvPrefix(fmt.Sprintf("%s_%d_", envName, count)) {
+		count++
+	}
+
+	if count == 0 {
+		return 0, "", nil
+	}
+
+	return count, envName, nil
+}
+
+// getElementEnvName returns the name of the environment variable of a field of a slice element (without the index prefix).
+// Every field of the elements can be set from the environment, the name is derived from the key unless the env tag sets it.
+func (c *Configurator) getElementEnvName(def fieldDefinition) string {
+	if def.envAlias != "" {
+		return def.envAlias
+	}
+
+	return strings.ToUpper(joinPrefix(def.key, c.getKeyDelimiter(), "_", c.splitWords || c.defaultCase&SnakeEnv != 0))
+}
+
+// hasEnvPrefix checks whether any environment variable starts with a prefix.
+func (c *Configurator) hasEnvPrefix(prefix string) bool {
+	environ := c.environ
+	if environ == nil {
+		environ = snapshotEnv()
+	}
+
+	for name := range environ {
+		if strings.HasPrefix(name, prefix) || (c.envCaseInsensitive && strings.HasPrefix(strings.ToUpper(name), prefix)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,144 @@
+package nest_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type indexedServer struct {
+	Host string
+	Port int `default:"80"`
+}
+
+func TestConfigurator_Load_Indexed(t *testing.T) {
+	type config struct {
+		Servers []indexedServer `env:""`
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_SERVERS_0_HOST", "a.example.com")
+	os.Setenv("APP_SERVERS_0_PORT", "8080")
+	os.Setenv("APP_SERVERS_1_HOST", "b.example.com")
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.SetEnvPrefix("app")
+
+	report, err := configurator.LoadWithReport(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, []indexedServer{{"a.example.com", 8080}, {"b.example.com", 80}}, actual.Servers)
+	assert.Equal(t, nest.OriginEnv, report.Field("Servers").Origin)
+	assert.Equal(t, map[string]string{
+		"APP_SERVERS_0_HOST": "a.example.com",
+		"APP_SERVERS_0_PORT": "8080",
+		"APP_SERVERS_1_HOST": "b.example.com",
+	}, report.Env)
+
+	os.Clearenv()
+}
+
+func TestConfigurator_Load_IndexedCount(t *testing.T) {
+	type config struct {
+		Servers []indexedServer `env:""`
+	}
+
+	os.Clearenv()
+	os.Setenv("SERVERS_COUNT", "3")
+	os.Setenv("SERVERS_0_HOST", "a.example.com")
+	os.Setenv("SERVERS_4_HOST", "ignored.example.com")
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, []indexedServer{{"a.example.com", 80}, {"", 80}, {"", 80}}, actual.Servers)
+
+	os.Clearenv()
+}
+
+func TestConfigurator_Load_IndexedInvalidCount(t *testing.T) {
+	type config struct {
+		Servers []indexedServer `env:""`
+	}
+
+	os.Clearenv()
+	os.Setenv("SERVERS_COUNT", "many")
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+
+	err := configurator.Load(&config{})
+	require.Error(t, err)
+	assert.EqualError(t, err, `invalid SERVERS_COUNT for field Servers: "many" is not a valid number of elements`)
+
+	os.Clearenv()
+}
+
+func TestConfigurator_Load_IndexedRequired(t *testing.T) {
+	type server struct {
+		Host string `required:"true"`
+	}
+
+	type config struct {
+		Servers []server `env:"" required:"true"`
+	}
+
+	os.Clearenv()
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+
+	err := configurator.Load(&config{})
+	require.Error(t, err)
+	assert.EqualError(t, err, "required field Servers missing value")
+
+	os.Setenv("SERVERS_COUNT", "1")
+
+	err = configurator.Load(&config{})
+	require.Error(t, err)
+	assert.EqualError(t, err, "required field Servers.0.Host missing value")
+
+	os.Clearenv()
+}
+
+func TestConfigurator_Load_IndexedWithoutEnvTag(t *testing.T) {
+	type config struct {
+		Servers []indexedServer
+	}
+
+	os.Clearenv()
+	os.Setenv("SERVERS_0_HOST", "a.example.com")
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+	assert.Nil(t, actual.Servers)
+
+	os.Clearenv()
+}
+
+func TestConfigurator_Load_IndexedFlag(t *testing.T) {
+	type config struct {
+		Servers []indexedServer `env:"" flag:""`
+	}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+
+	err := configurator.Load(&config{})
+	require.Error(t, err)
+	assert.EqualError(t, err, "field Servers is a slice of structs: it can only be loaded from indexed environment variables")
+}
@@ -0,0 +1,147 @@
+package nest
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// normalizeJSON5 converts the JSON5 syntax commonly found in hand-edited files into strict JSON:
+// comments are removed, trailing commas are dropped, single-quoted strings and unquoted keys are double-quoted.
+func normalizeJSON5(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	for i := 0; i < len(data); i++ {
+		ch := data[i]
+
+		switch {
+		case ch == '"' || ch == '\'':
+			end, err := writeJSON5String(&buf, data, i)
+			if err != nil {
+				return nil, err
+			}
+
+			i = end
+
+		case ch == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+
+			buf.WriteByte('\n')
+
+		case ch == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at offset %d", i)
+			}
+
+			i += end + 3
+			buf.WriteByte(' ')
+
+		case ch == ',':
+			// Drop the comma if the next significant character closes an object or array
+			if next := nextJSON5Token(data, i+1); next == '}' || next == ']' {
+				continue
+			}
+
+			buf.WriteByte(ch)
+
+		case isJSON5IdentStart(ch):
+			start := i
+			for i+1 < len(data) && isJSON5IdentPart(data[i+1]) {
+				i++
+			}
+
+			ident := data[start : i+1]
+
+			// Identifiers followed by a colon are unquoted keys, everything else (eg. true, null) is kept
+			if nextJSON5Token(data, i+1) == ':' {
+				buf.WriteByte('"')
+				buf.Write(ident)
+				buf.WriteByte('"')
+			} else {
+				buf.Write(ident)
+			}
+
+		default:
+			buf.WriteByte(ch)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeJSON5String writes a string literal starting at offset start as a double-quoted JSON string
+// and returns the offset of the closing quote.
+func writeJSON5String(buf *bytes.Buffer, data []byte, start int) (int, error) {
+	quote := data[start]
+
+	buf.WriteByte('"')
+
+	for i := start + 1; i < len(data); i++ {
+		ch := data[i]
+
+		switch {
+		case ch == '\\' && i+1 < len(data):
+			// Escaped single quotes are not valid in JSON
+			if data[i+1] == '\'' {
+				buf.WriteByte('\'')
+			} else {
+				buf.WriteByte(ch)
+				buf.WriteByte(data[i+1])
+			}
+
+			i++
+
+		case ch == quote:
+			buf.WriteByte('"')
+
+			return i, nil
+
+		case ch == '"':
+			buf.WriteString(`\"`)
+
+		default:
+			buf.WriteByte(ch)
+		}
+	}
+
+	return 0, fmt.Errorf("unterminated string at offset %d", start)
+}
+
+// nextJSON5Token returns the next character after offset i which is not whitespace or part of a comment.
+// It returns 0 at the end of the input.
+func nextJSON5Token(data []byte, i int) byte {
+	for i < len(data) {
+		switch {
+		case data[i] == ' ' || data[i] == '\t' || data[i] == '\n' || data[i] == '\r':
+			i++
+
+		case data[i] == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+
+		case data[i] == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				return 0
+			}
+
+			i += end + 4
+
+		default:
+			return data[i]
+		}
+	}
+
+	return 0
+}
+
+func isJSON5IdentStart(ch byte) bool {
+	return ch == '_' || ch == '$' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isJSON5IdentPart(ch byte) bool {
+	return isJSON5IdentStart(ch) || (ch >= '0' && ch <= '9')
+}
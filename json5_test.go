@@ -0,0 +1,33 @@
+package nest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeJSON5(t *testing.T) {
+	tests := map[string]string{
+		`{"a": 1}`:                        `{"a": 1}`,
+		`{a: 1, b_2: true,}`:              `{"a": 1, "b_2": true}`,
+		`{'a': 'it\'s "quoted"'}`:         `{"a": "it's \"quoted\""}`,
+		"{\"a\": 1, // comment\n}":        "{\"a\": 1 \n}",
+		`{"a": /* x */ [1, 2, /* y */ ]}`: `{"a":   [1, 2   ]}`,
+		`{"url": "http://example.com"}`:   `{"url": "http://example.com"}`,
+	}
+
+	for input, expected := range tests {
+		actual, err := normalizeJSON5([]byte(input))
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, string(actual), input)
+	}
+}
+
+func TestNormalizeJSON5_Unterminated(t *testing.T) {
+	_, err := normalizeJSON5([]byte(`{"a": "b}`))
+	assert.EqualError(t, err, "unterminated string at offset 6")
+
+	_, err = normalizeJSON5([]byte(`{"a": 1 /* }`))
+	assert.EqualError(t, err, "unterminated comment at offset 8")
+}
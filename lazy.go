@@ -0,0 +1,107 @@
+package nest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// lazyType is the reflected type of Lazy.
+var lazyType = reflect.TypeOf(Lazy{})
+
+// Lazy is a handle for a value looked up from the sources on first use instead of at load time,
+// for expensive (eg. remote) lookups that only some code paths need.
+//
+// Values set by flags, environment variables or overrides are resolved at load time as usual.
+// When the sources do not know about the value, the default value of the field is used.
+type Lazy struct {
+	value    string
+	resolved bool
+	fetch    func() (string, bool, error)
+
+	mu sync.Mutex
+}
+
+// NewLazy returns a Lazy holding a static value.
+func NewLazy(value string) *Lazy {
+	return &Lazy{
+		value:    value,
+		resolved: true,
+	}
+}
+
+// Decode implements the Decoder interface.
+func (l *Lazy) Decode(value string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.value = value
+	l.resolved = true
+
+	return nil
+}
+
+// Get returns the value, looking it up first if it has not been resolved yet.
+// Failed lookups are retried on the next call.
+func (l *Lazy) Get() (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.resolved && l.fetch != nil {
+		value, ok, err := l.fetch()
+		if err != nil {
+			return "", err
+		}
+
+		if ok {
+			l.value = value
+		}
+
+		l.resolved = true
+	}
+
+	return l.value, nil
+}
+
+// Scan resolves the value and parses it into the value pointed to by target (eg. a *int).
+func (l *Lazy) Scan(target interface{}) error {
+	ptr := reflect.ValueOf(target)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() {
+		return errors.New("scan target must be a non-nil pointer")
+	}
+
+	value, err := l.Get()
+	if err != nil {
+		return err
+	}
+
+	return processField(context.Background(), ptr.Elem(), value)
+}
+
+// bind configures the function looking up the value.
+func (l *Lazy) bind(fetch func() (string, bool, error)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.value = ""
+	l.resolved = false
+	l.fetch = fetch
+}
+
+// bindLazy configures a lazy field to look up it's value from the sources on first use.
+func (c *Configurator) bindLazy(def fieldDefinition) {
+	sources := c.sourcesByPriority(c.getSources())
+	key := def.key
+
+	def.field.Addr().Interface().(*Lazy).bind(func() (string, bool, error) {
+		value, ok, err := lookupSources(context.Background(), sources, key)
+		flushSources(sources)
+
+		if err != nil || ok {
+			return def.cleanValue(value), ok, err
+		}
+
+		return def.defaultValue, def.hasDefault, nil
+	})
+}
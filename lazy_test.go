@@ -0,0 +1,93 @@
+package nest_test
+
+import (
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingSource is a Source counting the lookups of each key.
+type countingSource struct {
+	values  map[string]string
+	lookups map[string]int
+}
+
+func (s *countingSource) Lookup(key string) (string, bool, error) {
+	s.lookups[key]++
+
+	value, ok := s.values[key]
+
+	return value, ok, nil
+}
+
+func TestLazy(t *testing.T) {
+	lazy := nest.NewLazy("42")
+
+	var value int
+	err := lazy.Scan(&value)
+	require.NoError(t, err)
+	assert.Equal(t, 42, value)
+
+	assert.EqualError(t, lazy.Scan(value), "scan target must be a non-nil pointer")
+}
+
+func TestConfigurator_Load_Lazy(t *testing.T) {
+	type config struct {
+		Token   nest.Lazy
+		Limit   *nest.Lazy `default:"10"`
+		Timeout nest.Lazy  `flag:"timeout"`
+	}
+
+	source := &countingSource{
+		values:  map[string]string{"Token": "token", "Timeout": "5s"},
+		lookups: make(map[string]int),
+	}
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app", "--timeout", "10s"})
+	configurator.AddSource(source)
+
+	err := configurator.Load(&c)
+	require.NoError(t, err)
+	assert.Empty(t, source.lookups)
+
+	for i := 0; i < 2; i++ {
+		value, err := c.Token.Get()
+		require.NoError(t, err)
+		assert.Equal(t, "token", value)
+	}
+
+	assert.Equal(t, map[string]int{"Token": 1}, source.lookups)
+
+	var limit int
+	err = c.Limit.Scan(&limit)
+	require.NoError(t, err)
+	assert.Equal(t, 10, limit)
+
+	value, err := c.Timeout.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "10s", value)
+	assert.Equal(t, map[string]int{"Token": 1, "Limit": 1}, source.lookups)
+}
+
+func TestConfigurator_Load_LazyError(t *testing.T) {
+	type config struct {
+		Token nest.Lazy
+	}
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.AddSource(failingSource{})
+
+	err := configurator.Load(&c)
+	require.NoError(t, err)
+
+	_, err = c.Token.Get()
+	assert.EqualError(t, err, "source unavailable")
+}
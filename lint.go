@@ -0,0 +1,363 @@
+package nest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// nestTags are the struct tags interpreted by the package.
+var nestTags = map[string]bool{
+	TagIgnored:              true,
+	TagIgnoreFields:         true,
+	TagDefault:              true,
+	TagRequired:             true,
+	TagEnum:                 true,
+	TagSplitWords:           true,
+	TagPrefix:               true,
+	TagDecode:               true,
+	TagAlias:                true,
+	TagAliasDeprecatedAfter: true,
+	TagEnvironment:          true,
+	TagFlag:                 true,
+	TagUsage:                true,
+	TagSection:              true,
+	TagExample:              true,
+	TagUnit:                 true,
+	TagFormat:               true,
+	TagWeight:               true,
+	TagSecret:               true,
+	TagTTL:                  true,
+	TagTemplate:             true,
+	TagTrim:                 true,
+	TagUnquote:              true,
+	TagSeparator:            true,
+	TagSetter:               true,
+	TagSourceOf:             true,
+	TagMetric:               true,
+	TagEnvconfig:            true,
+	TagDesc:                 true,
+}
+
+// lintTags checks the struct tags of a configuration struct for mistakes.
+// Every mistake is reported, not just the first one.
+func lintTags(structType reflect.Type, allowed map[string]bool) error {
+	var errs Errors
+
+	lintStruct(structType, "", allowed, make(map[reflect.Type]bool), &errs)
+
+	if len(errs) == 1 {
+		return errs[0]
+	} else if len(errs) > 1 {
+		return errs
+	}
+
+	return nil
+}
+
+// lintStruct checks the struct tags of the fields of a struct and it's child structs.
+func lintStruct(structType reflect.Type, prefix string, allowed map[string]bool, visited map[reflect.Type]bool, errs *Errors) {
+	// Recursive types are reported by the definition pass
+	if visited[structType] {
+		return
+	}
+
+	visited[structType] = true
+	defer delete(visited, structType)
+
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		name := prefix + structField.Name
+
+		if isExported(structField.Name) == false && !isEmbeddedStruct(structField) {
+			continue
+		}
+
+		for _, key := range tagKeys(structField.Tag) {
+			if !nestTags[key] && !allowed[key] && !isRegisteredTag(key) && !strings.HasPrefix(key, TagDefault+".") && isNestTagLike(key) {
+				*errs = append(*errs, fmt.Errorf("unknown tag %s on field %s", key, name))
+			}
+		}
+
+		tag := structField.Tag
+
+		value, ignored := tag.Lookup(TagIgnored)
+		ignored = ignored && isTrue(value)
+
+		if value, ok := tag.Lookup(TagRequired); ok && isTrue(value) && ignored {
+			*errs = append(*errs, fmt.Errorf("field %s is both required and ignored", name))
+		}
+
+		if _, ok := tag.Lookup(TagDefault); (ok || len(getModeDefaults(tag)) > 0) && ignored {
+			*errs = append(*errs, fmt.Errorf("field %s is ignored, but has a default value", name))
+		}
+
+		if value, ok := tag.Lookup(TagFlag); ok && strings.IndexFunc(value, unicode.IsSpace) >= 0 {
+			*errs = append(*errs, fmt.Errorf("invalid flag name %q on field %s", value, name))
+		}
+
+		if value, ok := tag.Lookup(TagEnvironment); ok && strings.IndexFunc(value, unicode.IsSpace) >= 0 {
+			*errs = append(*errs, fmt.Errorf("invalid environment variable name %q on field %s", value, name))
+		}
+
+		if ignored {
+			continue
+		}
+
+		fieldType := structField.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType.Kind() == reflect.Struct && fieldType != sourceInfoType && !canDecode(reflect.New(fieldType).Elem()) {
+			lintStruct(fieldType, name+".", allowed, visited, errs)
+
+			continue
+		}
+
+		if value, ok := tag.Lookup(TagDefault); ok {
+			if err := checkDefaultValue(fieldType, value, tagSeparator(tag)); err != nil {
+				*errs = append(*errs, fmt.Errorf("invalid default value %q for field %s: %v", value, name, err))
+			}
+		}
+
+		for _, key := range tagKeys(tag) {
+			if !strings.HasPrefix(key, TagDefault+".") {
+				continue
+			}
+
+			value, _ := tag.Lookup(key)
+			if err := checkDefaultValue(fieldType, value, tagSeparator(tag)); err != nil {
+				*errs = append(*errs, fmt.Errorf("invalid default value %q for field %s: %v", value, name, err))
+			}
+		}
+	}
+}
+
+// CheckDefaults checks whether the default values of a configuration struct can be parsed into their fields
+// (and are among the allowed values of the field, if any) without consulting any source.
+//
+// It is intended to be called from a unit test, so that invalid defaults fail the build instead of the startup.
+// The struct passed to CheckDefaults is not modified.
+func CheckDefaults(config interface{}) error {
+	target, err := getTarget(config)
+	if err != nil {
+		return err
+	}
+
+	definitions, err := getDefinitions(reflect.New(target.Type()).Elem())
+	if err != nil {
+		return err
+	}
+
+	var errs Errors
+
+	for _, def := range definitions {
+		var values []string
+
+		if def.hasDefault {
+			values = append(values, def.defaultValue)
+		}
+
+		// Defaults of every mode are checked
+		modes := make([]string, 0, len(def.modeDefaults))
+		for mode := range def.modeDefaults {
+			modes = append(modes, mode)
+		}
+
+		sort.Strings(modes)
+
+		for _, mode := range modes {
+			values = append(values, def.modeDefaults[mode])
+		}
+
+		for _, value := range values {
+			if len(def.choices) > 0 && !isChoice(def.choices, value) {
+				errs = append(errs, fmt.Errorf("invalid default value %q for field %s, must be one of: %s", value, def.key, strings.Join(def.choices, ", ")))
+
+				continue
+			}
+
+			if err := checkDefaultValue(def.field.Type(), value, def.separator); err != nil {
+				errs = append(errs, fmt.Errorf("invalid default value %q for field %s: %v", value, def.key, err))
+			}
+		}
+	}
+
+	if len(errs) == 1 {
+		return errs[0]
+	} else if len(errs) > 1 {
+		return errs
+	}
+
+	return nil
+}
+
+// checkDefaultValue checks whether a default value can be parsed into a type.
+// The separator splits the elements of slice values.
+func checkDefaultValue(typ reflect.Type, value string, separator string) error {
+	// Types which cannot be configured are skipped during loading as well
+	if isUnsupportedType(typ) {
+		return nil
+	}
+
+	return processSeparatedField(context.Background(), reflect.New(typ).Elem(), value, separator)
+}
+
+// tagSeparator returns the separator of slice elements set by a struct tag.
+func tagSeparator(tag reflect.StructTag) string {
+	if value, ok := tag.Lookup(TagSeparator); ok && value != "" {
+		return value
+	}
+
+	return defaultSeparator
+}
+
+// configurationTagKeys returns the keys of the tags interpreted by the package (or custom tag handlers) on a field,
+// except for the ignored tag.
+func configurationTagKeys(tag reflect.StructTag) []string {
+	var keys []string
+
+	for _, key := range tagKeys(tag) {
+		if key == TagIgnored {
+			continue
+		}
+
+		if nestTags[key] || isRegisteredTag(key) || strings.HasPrefix(key, TagDefault+".") {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// isNestTagLike checks whether an unknown tag key belongs to the namespace of the package:
+// it is either a misspelled tag (eg. defualt) or a tag with a suffix (eg. env_name, default_value).
+// Any other key is assumed to be interpreted by another library (eg. json, yaml, validate).
+func isNestTagLike(key string) bool {
+	maxDistance := 2
+	if len(key) <= 4 {
+		maxDistance = 1
+	}
+
+	for tag := range nestTags {
+		if strings.HasPrefix(key, tag+".") || strings.HasPrefix(key, tag+"_") {
+			return true
+		}
+
+		if editDistance(key, tag) <= maxDistance {
+			return true
+		}
+	}
+
+	return false
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and transpositions
+// needed to turn one string into the other.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(a)][len(b)]
+}
+
+// minInt returns the smallest of its arguments.
+func minInt(first int, rest ...int) int {
+	for _, value := range rest {
+		if value < first {
+			first = value
+		}
+	}
+
+	return first
+}
+
+// isRegisteredTag checks whether a custom tag handler is registered for a tag.
+func isRegisteredTag(name string) bool {
+	tagHandlersMu.RLock()
+	defer tagHandlersMu.RUnlock()
+
+	_, ok := tagHandlers[name]
+
+	return ok
+}
+
+// tagKeys returns the keys of a struct tag following the conventional key:"value" format.
+func tagKeys(tag reflect.StructTag) []string {
+	var keys []string
+
+	for tag != "" {
+		// Skip leading space
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		// Scan to colon
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+
+		name := string(tag[:i])
+		tag = tag[i+1:]
+
+		// Scan quoted string to find value
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+
+		if i >= len(tag) {
+			break
+		}
+
+		if _, err := strconv.Unquote(string(tag[:i+1])); err != nil {
+			break
+		}
+
+		keys = append(keys, name)
+		tag = tag[i+1:]
+	}
+
+	return keys
+}
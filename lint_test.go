@@ -0,0 +1,134 @@
+package nest_test
+
+import (
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurator_Load_StrictTags(t *testing.T) {
+	type config struct {
+		Host     string `defualt:"localhost" json:"host"`
+		Port     int    `default:"port"`
+		Internal string `ignored:"true" required:"true" default:"internal"`
+		Debug    bool   `flag:"debug mode"`
+
+		Database struct {
+			Name string `reqiured:"true"`
+		}
+	}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.SetStrictTags(true)
+
+	err := configurator.Load(&config{})
+	require.Error(t, err)
+
+	errs, ok := err.(nest.Errors)
+	require.True(t, ok)
+
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	assert.Equal(t, []string{
+		"unknown tag defualt on field Host",
+		`invalid default value "port" for field Port: strconv.ParseInt: parsing "port": invalid syntax`,
+		"field Internal is both required and ignored",
+		"field Internal is ignored, but has a default value",
+		`invalid flag name "debug mode" on field Debug`,
+		"unknown tag reqiured on field Database.Name",
+	}, messages)
+}
+
+func TestConfigurator_Load_StrictTagsAllowed(t *testing.T) {
+	type config struct {
+		Host string `default:"localhost" custom:"value"`
+	}
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.SetStrictTags(true, "custom")
+
+	err := configurator.Load(&c)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", c.Host)
+}
+
+func TestConfigurator_Load_StrictTagsForeign(t *testing.T) {
+	type config struct {
+		Host    string `default:"localhost" json:"host" yaml:"host" validate:"required,hostname" gorm:"column:host"`
+		Port    int    `default:"80" form:"port" env_name:"PORT"`
+		Timeout int    `default_value:"10" requird:"true"`
+	}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.SetStrictTags(true)
+
+	err := configurator.Load(&config{})
+	require.Error(t, err)
+
+	errs, ok := err.(nest.Errors)
+	require.True(t, ok)
+
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	assert.Equal(t, []string{
+		"unknown tag env_name on field Port",
+		"unknown tag default_value on field Timeout",
+		"unknown tag requird on field Timeout",
+	}, messages)
+}
+
+func TestConfigurator_Load_StrictTagsDisabled(t *testing.T) {
+	type config struct {
+		Host string `defualt:"localhost"`
+	}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+
+	err := configurator.Load(&config{})
+	assert.NoError(t, err)
+}
+
+func TestCheckDefaults(t *testing.T) {
+	type config struct {
+		Host  string `default:"localhost"`
+		Port  int    `default:"port"`
+		Level string `default:"verbose" enum:"debug,info"`
+		Pool  int    `default:"1" default.production:"many"`
+
+		Database *struct {
+			Port int `default:"3306"`
+		}
+	}
+
+	c := config{}
+
+	err := nest.CheckDefaults(&c)
+	assert.EqualError(t, err, `invalid default value "port" for field Port: strconv.ParseInt: parsing "port": invalid syntax; invalid default value "verbose" for field Level, must be one of: debug, info; invalid default value "many" for field Pool: strconv.ParseInt: parsing "many": invalid syntax`)
+
+	// The struct is not modified
+	assert.Equal(t, config{}, c)
+}
+
+func TestCheckDefaults_Valid(t *testing.T) {
+	type config struct {
+		Host string `default:"localhost"`
+		Port int    `default:"3306" default.production:"3307"`
+	}
+
+	assert.NoError(t, nest.CheckDefaults(&config{}))
+	assert.Equal(t, nest.ErrNotStructPointer, nest.CheckDefaults(config{}))
+}
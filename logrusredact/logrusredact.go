@@ -0,0 +1,61 @@
+// Package logrusredact hides the values of secret configuration fields in logrus log output.
+//
+// The hook redacts messages and fields using the Redactor of a load report:
+//
+//	report, err := configurator.LoadWithReport(&config)
+//	// ...
+//
+//	logger.AddHook(logrusredact.NewHook(report.Redactor()))
+package logrusredact
+
+import (
+	"fmt"
+
+	"github.com/goph/nest"
+	"github.com/sirupsen/logrus"
+)
+
+// Hook is a logrus.Hook hiding secret values in the message and the fields of every entry.
+//
+// Fields named after secret fields are hidden entirely, secret values are replaced in every other string,
+// error and fmt.Stringer field.
+type Hook struct {
+	redactor *nest.Redactor
+}
+
+// NewHook returns a new Hook hiding the secret values known by the redactor.
+func NewHook(redactor *nest.Redactor) *Hook {
+	return &Hook{
+		redactor: redactor,
+	}
+}
+
+// Levels implements the logrus.Hook interface.
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements the logrus.Hook interface.
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	entry.Message = h.redactor.Redact(entry.Message)
+
+	for key, value := range entry.Data {
+		switch v := value.(type) {
+		case string:
+			entry.Data[key] = h.redactor.RedactField(key, v)
+
+		case error:
+			entry.Data[key] = h.redactor.RedactField(key, v.Error())
+
+		case fmt.Stringer:
+			entry.Data[key] = h.redactor.RedactField(key, v.String())
+
+		default:
+			if h.redactor.IsSecretKey(key) {
+				entry.Data[key] = h.redactor.RedactField(key, "")
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package logrusredact_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/goph/nest/logrusredact"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHook(t *testing.T) {
+	type config struct {
+		Host     string `default:"example.com"`
+		Password string `default:"hunter2" secret:"true"`
+	}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+
+	report, err := configurator.LoadWithReport(&config{})
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	logger.AddHook(logrusredact.NewHook(report.Redactor()))
+
+	// Hooks are fired in the order they were added
+	hook := test.NewLocal(logger)
+
+	logger.WithFields(logrus.Fields{
+		"password": 1234,
+		"dsn":      "user:hunter2@example.com",
+		"error":    errors.New("login failed with hunter2"),
+		"port":     5432,
+	}).Info("connecting to example.com with hunter2")
+
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+
+	assert.Equal(t, "connecting to example.com with <hidden>", entry.Message)
+	assert.Equal(t, logrus.Fields{
+		"password": "<hidden>",
+		"dsn":      "user:<hidden>@example.com",
+		"error":    "login failed with <hidden>",
+		"port":     5432,
+	}, entry.Data)
+}
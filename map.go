@@ -0,0 +1,146 @@
+package nest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"unicode"
+)
+
+// LoadMap loads the configuration into a (nested) map instead of a struct,
+// which is useful when the configuration is passed on without a fixed structure (eg. to scripting engines).
+//
+// The map is a prototype: it's keys are the configuration keys, nested maps become nested keys,
+// the types of the values determine how the values are parsed and non-zero values are used as defaults.
+// Every key can be set by a flag and an environment variable (eg. --database-host and DATABASE_HOST for database.host).
+//
+// Keys must be valid Go identifiers. Like keys of struct fields, they are capitalized (eg. Database.Host)
+// when looked up in sources, but the resolved values are stored under the original keys.
+func (c *Configurator) LoadMap(config map[string]interface{}) error {
+	return c.LoadMapContext(context.Background(), config)
+}
+
+// LoadMapContext is the same as LoadMap, but it accepts a context which is passed to the sources.
+func (c *Configurator) LoadMapContext(ctx context.Context, config map[string]interface{}) error {
+	structType, err := getMapStructType(config)
+	if err != nil {
+		return err
+	}
+
+	target := reflect.New(structType)
+
+	if err := c.LoadContext(ctx, target.Interface()); err != nil {
+		return err
+	}
+
+	setMapValues(config, target.Elem())
+
+	return nil
+}
+
+// getMapStructType builds a struct type from a prototype map.
+func getMapStructType(config map[string]interface{}) (reflect.Type, error) {
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+
+	// Keep the order of the fields (and the help output) stable
+	sort.Strings(keys)
+
+	fields := make([]reflect.StructField, 0, len(keys))
+	names := make(map[string]string, len(keys))
+
+	for _, key := range keys {
+		name, err := getMapFieldName(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("configuration keys %s and %s are ambiguous", other, key)
+		}
+
+		names[name] = key
+
+		if nested, ok := config[key].(map[string]interface{}); ok {
+			nestedType, err := getMapStructType(nested)
+			if err != nil {
+				return nil, err
+			}
+
+			fields = append(fields, reflect.StructField{
+				Name: name,
+				Type: nestedType,
+			})
+
+			continue
+		}
+
+		value := config[key]
+
+		// Keys without a typed value are strings
+		if value == nil {
+			value = ""
+		}
+
+		tag := `flag:"" env:""`
+		if !isZeroValueOfType(value) {
+			tag += " default:" + strconv.Quote(formatValue(value))
+		}
+
+		fields = append(fields, reflect.StructField{
+			Name: name,
+			Type: reflect.TypeOf(value),
+			Tag:  reflect.StructTag(tag),
+		})
+	}
+
+	return reflect.StructOf(fields), nil
+}
+
+// getMapFieldName converts a map key to an exported field name.
+func getMapFieldName(key string) (string, error) {
+	if key == "" {
+		return "", fmt.Errorf("invalid configuration key %q: keys must be valid identifiers", key)
+	}
+
+	for i, r := range key {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || !unicode.IsDigit(r)) {
+			return "", fmt.Errorf("invalid configuration key %q: keys must be valid identifiers", key)
+		}
+	}
+
+	name := []rune(key)
+	name[0] = unicode.ToUpper(name[0])
+
+	// Fields starting with an underscore cannot be exported
+	if name[0] == '_' {
+		return "", fmt.Errorf("invalid configuration key %q: keys must start with a letter", key)
+	}
+
+	return string(name), nil
+}
+
+// setMapValues copies the loaded values from a struct built by getMapStructType to the prototype map.
+func setMapValues(config map[string]interface{}, structValue reflect.Value) {
+	for key, value := range config {
+		name, _ := getMapFieldName(key)
+		field := structValue.FieldByName(name)
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			setMapValues(nested, field)
+
+			continue
+		}
+
+		// Values which cannot be configured are kept
+		if isUnsupportedType(field.Type()) {
+			continue
+		}
+
+		config[key] = field.Interface()
+	}
+}
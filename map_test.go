@@ -0,0 +1,71 @@
+package nest_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurator_LoadMap(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("DATABASE_HOST", "db.example.com")
+
+	config := map[string]interface{}{
+		"debug": false,
+		"database": map[string]interface{}{
+			"host": "localhost",
+			"port": 3306,
+			"user": nil,
+		},
+		"servers": []string{"a", "b"},
+	}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app", "--debug", "--database-port", "5432"})
+	configurator.AddSource(mapSource{"Database.User": "admin"})
+
+	err := configurator.LoadMap(config)
+	require.NoError(t, err)
+
+	expected := map[string]interface{}{
+		"debug": true,
+		"database": map[string]interface{}{
+			"host": "db.example.com",
+			"port": 5432,
+			"user": "admin",
+		},
+		"servers": []string{"a", "b"},
+	}
+
+	assert.Equal(t, expected, config)
+
+	os.Clearenv()
+}
+
+func TestConfigurator_LoadMap_Defaults(t *testing.T) {
+	config := map[string]interface{}{
+		"host": "localhost",
+		"port": 3306,
+	}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+
+	err := configurator.LoadMap(config)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"host": "localhost", "port": 3306}, config)
+}
+
+func TestConfigurator_LoadMap_InvalidKey(t *testing.T) {
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+
+	err := configurator.LoadMap(map[string]interface{}{"database-host": ""})
+	assert.EqualError(t, err, `invalid configuration key "database-host": keys must be valid identifiers`)
+
+	err = configurator.LoadMap(map[string]interface{}{"host": "", "Host": ""})
+	assert.EqualError(t, err, "configuration keys Host and host are ambiguous")
+}
@@ -0,0 +1,101 @@
+// Package nesttest provides helpers for testing applications using nest.
+package nesttest
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goph/nest"
+)
+
+// update rewrites the golden files instead of comparing them.
+var update = flag.Bool("nesttest.update", false, "update the golden files of nesttest")
+
+// Golden compares the configuration keys, flag and environment variable names derived from a configuration struct
+// with the golden file testdata/<test name>.golden, so that accidental renames are caught in code review.
+//
+// Run the tests with the -nesttest.update flag to create or update the golden files.
+func Golden(t testing.TB, config interface{}) {
+	GoldenConfigurator(t, nest.NewConfigurator(), config)
+}
+
+// GoldenConfigurator is the same as Golden, but it uses the naming options of a configurator.
+// Environment variable names are written without the prefix.
+func GoldenConfigurator(t testing.TB, configurator *nest.Configurator, config interface{}) {
+	name := strings.Replace(t.Name(), "/", "_", -1)
+
+	if err := golden(filepath.Join("testdata", name+".golden"), configurator, config, *update); err != nil {
+		t.Error(err)
+	}
+}
+
+// golden compares (or updates) a golden file.
+func golden(path string, configurator *nest.Configurator, config interface{}, update bool) error {
+	schema, err := configurator.Parse(config)
+	if err != nil {
+		return err
+	}
+
+	actual := render(schema)
+
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+
+		return ioutil.WriteFile(path, actual, 0644)
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("golden file %s does not exist, run the tests with -nesttest.update to create it", path)
+	} else if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(expected, actual) {
+		return fmt.Errorf(
+			"configuration names do not match golden file %s (run the tests with -nesttest.update if the change is intended)\n\nexpected:\n%s\nactual:\n%s",
+			path,
+			expected,
+			actual,
+		)
+	}
+
+	return nil
+}
+
+// render writes the names of every field in declaration order, one line per field.
+func render(schema *nest.Schema) []byte {
+	var buf bytes.Buffer
+
+	for _, field := range schema.Fields {
+		buf.WriteString(field.Key)
+
+		if field.Flag != "" {
+			fmt.Fprintf(&buf, " flag=%s", field.Flag)
+		}
+
+		if field.Env != "" {
+			fmt.Fprintf(&buf, " env=%s", field.Env)
+		}
+
+		for _, alias := range field.EnvAliases {
+			fmt.Fprintf(&buf, " env=%s", alias)
+		}
+
+		for _, alias := range field.Aliases {
+			fmt.Fprintf(&buf, " alias=%s", alias)
+		}
+
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes()
+}
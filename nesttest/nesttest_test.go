@@ -0,0 +1,58 @@
+package nesttest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type config struct {
+	Debug bool `flag:"" env:""`
+
+	Database struct {
+		Host string `flag:"" env:"" alias:"db.host"`
+		Port int    `env:"port"`
+	}
+}
+
+func TestGolden(t *testing.T) {
+	Golden(t, &config{})
+}
+
+func TestGolden_Mismatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nesttest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.golden")
+
+	err = golden(path, nest.NewConfigurator(), &config{}, false)
+	assert.EqualError(t, err, "golden file "+path+" does not exist, run the tests with -nesttest.update to create it")
+
+	err = golden(path, nest.NewConfigurator(), &config{}, true)
+	require.NoError(t, err)
+
+	err = golden(path, nest.NewConfigurator(), &config{}, false)
+	require.NoError(t, err)
+
+	configurator := nest.NewConfigurator()
+	configurator.SetDefaultCase(nest.SnakeEnv, nest.SpinalFlags)
+
+	type renamed struct {
+		Debug bool `flag:"" env:""`
+
+		Database struct {
+			Hostname string `flag:"" env:""`
+			Port     int    `env:"port"`
+		}
+	}
+
+	err = golden(path, configurator, &renamed{}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Database.Hostname flag=database-hostname env=DATABASE_HOSTNAME")
+}
@@ -0,0 +1,128 @@
+// Package plistsource implements a source reading values from macOS property lists and defaults domains
+// (eg. settings installed by MDM configuration profiles).
+//
+// Nested dictionaries are flattened using dots as key separators,
+// arrays are joined with commas (matching the format of slice flags and environment variables).
+//
+// The sources are only available on darwin.
+package plistsource
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// parse reads an XML property list and returns it's flattened values.
+func parse(r io.Reader) (map[string]string, error) {
+	decoder := xml.NewDecoder(r)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("invalid property list: missing root dictionary")
+		} else if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local == "plist" {
+			continue
+		}
+
+		if start.Name.Local != "dict" {
+			return nil, fmt.Errorf("invalid property list: root element is %s, expected dict", start.Name.Local)
+		}
+
+		values := make(map[string]string)
+
+		if err := parseDict(decoder, "", values); err != nil {
+			return nil, err
+		}
+
+		return values, nil
+	}
+}
+
+// parseDict reads the entries of a dict element (after it's start element).
+func parseDict(decoder *xml.Decoder, prefix string, values map[string]string) error {
+	var key string
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.EndElement:
+			return nil
+
+		case xml.StartElement:
+			if t.Name.Local == "key" {
+				if err := decoder.DecodeElement(&key, &t); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			if t.Name.Local == "dict" {
+				if err := parseDict(decoder, prefix+key+".", values); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			value, err := parseValue(decoder, t)
+			if err != nil {
+				return fmt.Errorf("invalid value for key %s%s: %v", prefix, key, err)
+			}
+
+			values[prefix+key] = value
+		}
+	}
+}
+
+// parseValue reads a scalar or array element.
+func parseValue(decoder *xml.Decoder, start xml.StartElement) (string, error) {
+	switch start.Name.Local {
+	case "true", "false":
+		return start.Name.Local, decoder.Skip()
+
+	case "string", "integer", "real", "date", "data":
+		var value string
+		if err := decoder.DecodeElement(&value, &start); err != nil {
+			return "", err
+		}
+
+		return strings.TrimSpace(value), nil
+
+	case "array":
+		var items []string
+
+		for {
+			token, err := decoder.Token()
+			if err != nil {
+				return "", err
+			}
+
+			switch t := token.(type) {
+			case xml.EndElement:
+				return strings.Join(items, ","), nil
+
+			case xml.StartElement:
+				item, err := parseValue(decoder, t)
+				if err != nil {
+					return "", err
+				}
+
+				items = append(items, item)
+			}
+		}
+	}
+
+	return "", fmt.Errorf("unsupported element %s", start.Name.Local)
+}
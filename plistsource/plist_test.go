@@ -0,0 +1,53 @@
+package plistsource
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	plist := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Debug</key>
+	<true/>
+	<key>Database</key>
+	<dict>
+		<key>Host</key>
+		<string>db.example.com</string>
+		<key>Port</key>
+		<integer>3306</integer>
+	</dict>
+	<key>Ratio</key>
+	<real>0.5</real>
+	<key>Servers</key>
+	<array>
+		<string>a</string>
+		<string>b</string>
+	</array>
+</dict>
+</plist>`
+
+	values, err := parse(strings.NewReader(plist))
+	require.NoError(t, err)
+
+	expected := map[string]string{
+		"Debug":         "true",
+		"Database.Host": "db.example.com",
+		"Database.Port": "3306",
+		"Ratio":         "0.5",
+		"Servers":       "a,b",
+	}
+
+	assert.Equal(t, expected, values)
+}
+
+func TestParse_InvalidRoot(t *testing.T) {
+	_, err := parse(strings.NewReader(`<plist version="1.0"><array></array></plist>`))
+
+	assert.EqualError(t, err, "invalid property list: root element is array, expected dict")
+}
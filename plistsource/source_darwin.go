@@ -0,0 +1,72 @@
+//go:build darwin
+// +build darwin
+
+package plistsource
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Source looks up values from a property list.
+//
+// The property list is read on the first lookup.
+type Source struct {
+	command []string
+
+	values map[string]string
+	mu     sync.Mutex
+}
+
+// NewSource returns a new Source reading the property list file located at path.
+// Both XML and binary property lists are supported.
+func NewSource(path string) *Source {
+	return &Source{
+		command: []string{"plutil", "-convert", "xml1", "-o", "-", path},
+	}
+}
+
+// NewDomainSource returns a new Source reading a defaults domain (eg. com.example.agent).
+// Managed preferences installed by configuration profiles are included.
+func NewDomainSource(domain string) *Source {
+	return &Source{
+		command: []string{"defaults", "export", domain, "-"},
+	}
+}
+
+// Lookup implements the nest.Source interface.
+func (s *Source) Lookup(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.values == nil {
+		values, err := s.load()
+		if err != nil {
+			return "", false, err
+		}
+
+		s.values = values
+	}
+
+	value, ok := s.values[key]
+
+	return value, ok, nil
+}
+
+// load runs the command exporting the property list and parses it's output.
+func (s *Source) load() (map[string]string, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command(s.command[0], s.command[1:]...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %v: %s", s.command[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parse(bytes.NewReader(out))
+}
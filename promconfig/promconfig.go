@@ -0,0 +1,105 @@
+// Package promconfig exports configuration values as Prometheus metrics,
+// so that dashboards can display what configuration each instance is running with.
+//
+// Only fields tagged with `metric:"true"` are exported:
+//
+//	collector := promconfig.NewCollector("myapp")
+//	configurator.OnAfterLoad(collector.Record)
+//
+//	prometheus.MustRegister(collector)
+package promconfig
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/goph/nest"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector is a prometheus.Collector exporting the values of the last load.
+//
+// Every field is exported as an info metric (with the value as a label),
+// numeric and boolean values are exported as gauges as well.
+// Secret fields are never exported.
+type Collector struct {
+	infoDesc  *prometheus.Desc
+	valueDesc *prometheus.Desc
+
+	fields []nest.FieldReport
+
+	mu sync.RWMutex
+}
+
+// NewCollector returns a new Collector.
+func NewCollector(namespace string) *Collector {
+	return &Collector{
+		infoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "config", "info"),
+			"Configuration values of the instance.",
+			[]string{"key", "value", "origin"},
+			nil,
+		),
+		valueDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "config", "value"),
+			"Numeric configuration values of the instance.",
+			[]string{"key"},
+			nil,
+		),
+	}
+}
+
+// Record stores the values of a load.
+// It's signature makes it usable as an after load hook.
+func (c *Collector) Record(config interface{}, report *nest.Report) {
+	var fields []nest.FieldReport
+
+	for _, field := range report.Fields {
+		if field.Metric && !field.Secret {
+			fields = append(fields, field)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fields = fields
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.infoDesc
+	ch <- c.valueDesc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, field := range c.fields {
+		ch <- prometheus.MustNewConstMetric(c.infoDesc, prometheus.GaugeValue, 1, field.Key, field.Value, string(field.Origin))
+
+		if value, ok := parseNumber(field.Value); ok {
+			ch <- prometheus.MustNewConstMetric(c.valueDesc, prometheus.GaugeValue, value, field.Key)
+		}
+	}
+}
+
+// parseNumber parses numeric and boolean values.
+func parseNumber(value string) (float64, bool) {
+	if b, err := strconv.ParseBool(value); err == nil {
+		if b {
+			return 1, true
+		}
+
+		return 0, true
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return f, true
+}
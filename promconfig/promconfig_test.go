@@ -0,0 +1,46 @@
+package promconfig_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/goph/nest/promconfig"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector(t *testing.T) {
+	type config struct {
+		Host     string `flag:"host" default:"localhost" metric:"true"`
+		Port     int    `default:"8080" metric:"true"`
+		Debug    bool   `flag:"debug" metric:"true"`
+		Password string `default:"s3cr3t" secret:"true" metric:"true"`
+		Other    string `default:"other"`
+	}
+
+	collector := promconfig.NewCollector("app")
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app", "--debug"})
+	configurator.OnAfterLoad(collector.Record)
+
+	err := configurator.Load(&config{})
+	require.NoError(t, err)
+
+	expected := `
+# HELP app_config_info Configuration values of the instance.
+# TYPE app_config_info gauge
+app_config_info{key="Debug",origin="flag",value="true"} 1
+app_config_info{key="Host",origin="default",value="localhost"} 1
+app_config_info{key="Port",origin="default",value="8080"} 1
+# HELP app_config_value Numeric configuration values of the instance.
+# TYPE app_config_value gauge
+app_config_value{key="Debug"} 1
+app_config_value{key="Port"} 8080
+`
+
+	err = testutil.CollectAndCompare(collector, strings.NewReader(expected))
+	assert.NoError(t, err)
+}
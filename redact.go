@@ -0,0 +1,102 @@
+package nest
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Redactor hides the values of secret fields in log output.
+//
+// It's created from the report of a load (see Report.Redactor) and refers to the secret fields of the loaded configuration,
+// so it hides their current values (eg. rotated Secret values) and stops hiding SecretString values once they are closed.
+// It can be plugged into any logger supporting message or field hooks: see ReplaceAttr for slog,
+// the zapredact package for zap and the logrusredact package for logrus.
+type Redactor struct {
+	keys   map[string]bool
+	values []func() string
+
+	// Replacer built from the last seen secret values
+	current  []string
+	replacer *strings.Replacer
+
+	mu sync.Mutex
+}
+
+// newRedactor creates a redactor from the keys of the secret fields and functions returning their current values.
+func newRedactor(keys []string, values []func() string) *Redactor {
+	r := &Redactor{
+		keys:   make(map[string]bool, len(keys)),
+		values: values,
+	}
+
+	for _, key := range keys {
+		r.keys[strings.ToLower(key)] = true
+	}
+
+	return r
+}
+
+// getReplacer returns a replacer hiding the current secret values (or nil if there are none).
+// The replacer is only rebuilt when the values change.
+func (r *Redactor) getReplacer() *strings.Replacer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	values := make([]string, 0, len(r.values))
+	for _, value := range r.values {
+		if v := value(); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	// Longer values are replaced first, so that values containing others are hidden entirely
+	sort.Slice(values, func(i, j int) bool {
+		return len(values[i]) > len(values[j])
+	})
+
+	if reflect.DeepEqual(values, r.current) {
+		return r.replacer
+	}
+
+	r.current = values
+	r.replacer = nil
+
+	if len(values) > 0 {
+		pairs := make([]string, 0, 2*len(values))
+		for _, value := range values {
+			pairs = append(pairs, value, hiddenValue)
+		}
+
+		r.replacer = strings.NewReplacer(pairs...)
+	}
+
+	return r.replacer
+}
+
+// Redact replaces the secret values in a string.
+func (r *Redactor) Redact(s string) string {
+	replacer := r.getReplacer()
+	if replacer == nil {
+		return s
+	}
+
+	return replacer.Replace(s)
+}
+
+// IsSecretKey checks whether a key (eg. a log field name) is the key of a secret field.
+// Keys are matched case-insensitively, so that both Database.Password and database.password match.
+func (r *Redactor) IsSecretKey(key string) bool {
+	return r.keys[strings.ToLower(key)]
+}
+
+// RedactField returns the value to log for a field: hidden if the key belongs to a secret field,
+// otherwise the value with every secret value replaced.
+func (r *Redactor) RedactField(key string, value string) string {
+	if r.IsSecretKey(key) {
+		return hiddenValue
+	}
+
+	return r.Redact(value)
+}
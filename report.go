@@ -0,0 +1,297 @@
+package nest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Origin describes where the value of a field comes from.
+type Origin string
+
+// Possible origins of values.
+const (
+	OriginNone     Origin = ""
+	OriginOverride Origin = "override"
+	OriginFlag     Origin = "flag"
+	OriginEnv      Origin = "env"
+	OriginSource   Origin = "source"
+	OriginPrefill  Origin = "prefill"
+	OriginDefault  Origin = "default"
+)
+
+// SourceInfo reports where the value of a field comes from.
+//
+// Fields of this type are populated by Load when they reference another field with the source_of tag:
+//
+//	type Config struct {
+//		Host       string     `env:"host"`
+//		HostSource SourceInfo `source_of:"Host"`
+//	}
+type SourceInfo struct {
+	Origin Origin
+
+	// Name is the name of the flag or environment variable the value was read from.
+	Name string
+}
+
+// sourceInfoType is the reflected type of SourceInfo.
+var sourceInfoType = reflect.TypeOf(SourceInfo{})
+
+// FieldReport describes the outcome of loading a single field.
+type FieldReport struct {
+	Key    string `json:"key"`
+	Origin Origin `json:"origin,omitempty"`
+
+	// Name is the name of the flag or environment variable the value was read from.
+	Name string `json:"name,omitempty"`
+
+	// Value is the loaded value formatted as string (hidden for secret fields).
+	Value  string `json:"value"`
+	Secret bool   `json:"secret,omitempty"`
+
+	// Metric reports whether the field should be exported as a metric (configured with the metric tag).
+	Metric bool `json:"metric,omitempty"`
+}
+
+// Report describes the outcome of loading the configuration.
+type Report struct {
+	Fields []FieldReport
+
+	// Warnings raised during loading (eg. usage of deprecated environment variables).
+	Warnings []string
+
+	// Duration is the time spent loading the configuration.
+	Duration time.Duration
+
+	// Env contains the environment variables the values of the fields were resolved from
+	// (the values of secret fields are hidden). Other variables of the environment are not retained.
+	// It may still contain sensitive values, so it's never serialized.
+	Env map[string]string `json:"-"`
+
+	// Keys of the secret fields and functions returning their current values (used by Redactor),
+	// the values themselves are not retained
+	secretKeys   []string
+	secretValues []func() string
+}
+
+// Field returns the report of the field with the given key or nil if there is no such field.
+func (r *Report) Field(key string) *FieldReport {
+	for i := range r.Fields {
+		if r.Fields[i].Key == key {
+			return &r.Fields[i]
+		}
+	}
+
+	return nil
+}
+
+// Origins returns the origin of every field by key.
+func (r *Report) Origins() map[string]Origin {
+	origins := make(map[string]Origin, len(r.Fields))
+
+	for _, field := range r.Fields {
+		origins[field.Key] = field.Origin
+	}
+
+	return origins
+}
+
+// Defaults returns the keys of the fields which received their default value.
+func (r *Report) Defaults() []string {
+	var keys []string
+
+	for _, field := range r.Fields {
+		if field.Origin == OriginDefault {
+			keys = append(keys, field.Key)
+		}
+	}
+
+	return keys
+}
+
+// SetKeys returns the keys of the fields explicitly set by an override, flag, environment variable, source or prefilled value.
+//
+// It can be used for applying only the explicitly set options (eg. when reconfiguring a running application).
+func (r *Report) SetKeys() []string {
+	var keys []string
+
+	for _, field := range r.Fields {
+		if field.Origin != OriginNone && field.Origin != OriginDefault {
+			keys = append(keys, field.Key)
+		}
+	}
+
+	return keys
+}
+
+// UnsetKeys returns the keys of the fields which were not explicitly set:
+// they either received their default value (see Defaults) or kept their zero value.
+func (r *Report) UnsetKeys() []string {
+	var keys []string
+
+	for _, field := range r.Fields {
+		if field.Origin == OriginNone || field.Origin == OriginDefault {
+			keys = append(keys, field.Key)
+		}
+	}
+
+	return keys
+}
+
+// Fingerprint returns a stable hash (hex encoded SHA-256) of the loaded configuration values.
+//
+// It only depends on the keys and the reported values (not on their origin),
+// so replicas running with identical configuration have the same fingerprint.
+// Since values of secret fields are hidden in the report, changing them doesn't change the fingerprint.
+func (r *Report) Fingerprint() string {
+	fields := make([]FieldReport, len(r.Fields))
+	copy(fields, r.Fields)
+
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Key < fields[j].Key
+	})
+
+	h := sha256.New()
+
+	for _, field := range fields {
+		fmt.Fprintf(h, "%d:%s=%d:%s\n", len(field.Key), field.Key, len(field.Value), field.Value)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Redactor returns a redactor hiding the values of the secret fields of the loaded configuration in log output.
+func (r *Report) Redactor() *Redactor {
+	return newRedactor(r.secretKeys, r.secretValues)
+}
+
+// LogFields returns the loaded values by key with secret values hidden,
+// so that the configuration can be logged as structured fields (eg. with logrus.WithFields or zap.Any).
+func (r *Report) LogFields() map[string]interface{} {
+	fields := make(map[string]interface{}, len(r.Fields))
+
+	for _, field := range r.Fields {
+		fields[field.Key] = field.Value
+	}
+
+	return fields
+}
+
+// CrashInfo is the configuration context attached to crash reports (see Report.Recover).
+// Values of secret fields are hidden.
+type CrashInfo struct {
+	Fingerprint string            `json:"fingerprint"`
+	Values      map[string]string `json:"values"`
+	Origins     map[string]Origin `json:"origins"`
+	Warnings    []string          `json:"warnings,omitempty"`
+}
+
+// CrashInfo returns the configuration context to attach to crash reports
+// (eg. as the context of an error tracking service's scope).
+func (r *Report) CrashInfo() CrashInfo {
+	values := make(map[string]string, len(r.Fields))
+
+	for _, field := range r.Fields {
+		values[field.Key] = field.Value
+	}
+
+	return CrashInfo{
+		Fingerprint: r.Fingerprint(),
+		Values:      values,
+		Origins:     r.Origins(),
+		Warnings:    r.Warnings,
+	}
+}
+
+// Recover passes panics to a crash reporter along with the configuration context, then continues panicking.
+// It must be deferred directly:
+//
+//	defer report.Recover(func(recovered interface{}, info nest.CrashInfo) {
+//		// send the crash to an error tracking service
+//	})
+func (r *Report) Recover(report func(recovered interface{}, info CrashInfo)) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	report(recovered, r.CrashInfo())
+
+	panic(recovered)
+}
+
+// writeSummary writes the loaded values annotated with their origin (eg. Host = localhost (env HOST)).
+func writeSummary(w io.Writer, report *Report) {
+	fmt.Fprintln(w, "Configuration:")
+
+	for _, field := range report.Fields {
+		annotation := string(field.Origin)
+		if field.Name != "" {
+			annotation += " " + field.Name
+		}
+
+		if field.Origin == OriginNone {
+			annotation = "not set"
+		}
+
+		value := field.Value
+		if value == "" {
+			value = `""`
+		}
+
+		fmt.Fprintf(w, "  %s = %s (%s)\n", field.Key, value, annotation)
+	}
+}
+
+// addEnv records an environment variable a value was resolved from, hiding the value if the field is secret.
+func (r *Report) addEnv(name string, value string, secret bool) {
+	if secret {
+		value = hiddenValue
+	}
+
+	r.Env[name] = value
+}
+
+// secretValue returns a function returning the current value of a secret field formatted as string.
+func secretValue(def fieldDefinition) func() string {
+	field := def.field
+	separator := def.separator
+
+	switch field.Type() {
+	case secretType:
+		secret := field.Addr().Interface().(*Secret)
+
+		return secret.current
+
+	case secretStringType:
+		return func() string {
+			return field.Interface().(SecretString).Value()
+		}
+	}
+
+	return func() string {
+		if separator != "" {
+			return formatSeparatedValue(field.Interface(), separator)
+		}
+
+		return formatValue(field.Interface())
+	}
+}
+
+// formatReportValue formats the value of a field for the report.
+func formatReportValue(def fieldDefinition) string {
+	if def.secret {
+		return hiddenValue
+	}
+
+	if def.separator != "" {
+		return formatSeparatedValue(def.field.Interface(), def.separator)
+	}
+
+	return formatValue(def.field.Interface())
+}
@@ -0,0 +1,313 @@
+package nest_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurator_LoadWithReport(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_ENV", "production")
+	os.Setenv("OLD_PORT", "8080")
+
+	type config struct {
+		Flag     string `flag:"flag"`
+		Env      string `env:"env"`
+		Port     int    `env:"port"`
+		Source   string
+		Default  string `default:"default"`
+		Override string
+		Password string `flag:"password" secret:"true"`
+		None     string
+	}
+
+	c := config{
+		Override: "override",
+	}
+
+	var buf bytes.Buffer
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app", "--flag", "flag", "--password", "secret"})
+	configurator.SetEnvPrefixes("app", "old")
+	configurator.SetErrorOutput(&buf)
+	configurator.AddSource(mapSource{"Source": "source"})
+
+	report, err := configurator.LoadWithReport(&c)
+	require.NoError(t, err)
+
+	assert.Equal(t, []nest.FieldReport{
+		{Key: "Flag", Origin: nest.OriginFlag, Name: "--flag", Value: "flag"},
+		{Key: "Env", Origin: nest.OriginEnv, Name: "APP_ENV", Value: "production"},
+		{Key: "Port", Origin: nest.OriginEnv, Name: "OLD_PORT", Value: "8080"},
+		{Key: "Source", Origin: nest.OriginSource, Value: "source"},
+		{Key: "Default", Origin: nest.OriginDefault, Value: "default"},
+		{Key: "Override", Origin: nest.OriginOverride, Value: "override"},
+		{Key: "Password", Origin: nest.OriginFlag, Name: "--password", Value: "<hidden>", Secret: true},
+		{Key: "None", Origin: nest.OriginNone, Value: ""},
+	}, report.Fields)
+
+	assert.Equal(t, []string{"environment variable OLD_PORT is deprecated, use APP_PORT instead"}, report.Warnings)
+	assert.Equal(t, "Warning: environment variable OLD_PORT is deprecated, use APP_PORT instead\n", buf.String())
+	assert.Equal(t, []string{"Default"}, report.Defaults())
+	assert.Equal(t, []string{"Flag", "Env", "Port", "Source", "Override", "Password"}, report.SetKeys())
+	assert.Equal(t, []string{"Default", "None"}, report.UnsetKeys())
+	assert.Equal(t, nest.OriginSource, report.Field("Source").Origin)
+	assert.Nil(t, report.Field("Missing"))
+
+	os.Clearenv()
+}
+
+func TestSourceInfo(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("HOST", "example.com")
+
+	type config struct {
+		HostSource nest.SourceInfo `source_of:"Host"`
+		Host       string          `env:"host"`
+		Database   struct {
+			Port       int             `default:"3306"`
+			PortSource nest.SourceInfo `source_of:"Port"`
+		}
+	}
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+
+	report, err := configurator.LoadWithReport(&c)
+	require.NoError(t, err)
+
+	assert.Equal(t, nest.SourceInfo{Origin: nest.OriginEnv, Name: "HOST"}, c.HostSource)
+	assert.Equal(t, nest.SourceInfo{Origin: nest.OriginDefault}, c.Database.PortSource)
+	assert.Equal(t, map[string]nest.Origin{"Host": nest.OriginEnv, "Database.Port": nest.OriginDefault}, report.Origins())
+
+	os.Clearenv()
+}
+
+func TestSourceInfo_UnknownField(t *testing.T) {
+	type config struct {
+		Source nest.SourceInfo `source_of:"Missing"`
+	}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+
+	err := configurator.Load(&config{})
+	assert.EqualError(t, err, "source_of tag references unknown field Missing")
+}
+
+// mutatingSource changes the environment when it's consulted.
+type mutatingSource struct{}
+
+func (mutatingSource) Lookup(key string) (string, bool, error) {
+	os.Setenv("HOST", "changed")
+
+	return "", false, nil
+}
+
+func TestConfigurator_LoadWithReport_EnvSnapshot(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("HOST", "example.com")
+
+	type config struct {
+		Host string `env:"host"`
+	}
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.AddSource(mutatingSource{})
+
+	report, err := configurator.LoadWithReport(&c)
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com", c.Host)
+	assert.Equal(t, "example.com", report.Env["HOST"])
+	assert.Equal(t, "changed", os.Getenv("HOST"))
+
+	os.Clearenv()
+}
+
+func TestConfigurator_LoadWithReport_Env(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("HOST", "example.com")
+	os.Setenv("PORT", "8080")
+	os.Setenv("PASSWORD", "secret")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "unrelated")
+
+	type config struct {
+		Host     string `env:"host"`
+		Port     int    `env:"port" flag:"port"`
+		Password string `env:"password" secret:"true"`
+	}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app", "--port", "80"})
+
+	report, err := configurator.LoadWithReport(&config{})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"HOST":     "example.com",
+		"PASSWORD": "<hidden>",
+	}, report.Env)
+
+	os.Clearenv()
+}
+
+func TestReport_Fingerprint(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Host     string `env:"host" flag:"host"`
+		Port     int    `default:"8080"`
+		Password string `env:"password" secret:"true"`
+	}
+
+	load := func(args ...string) *nest.Report {
+		configurator := nest.NewConfigurator()
+		configurator.SetArgs(append([]string{"app"}, args...))
+
+		report, err := configurator.LoadWithReport(&config{})
+		require.NoError(t, err)
+
+		return report
+	}
+
+	os.Setenv("HOST", "example.com")
+	os.Setenv("PASSWORD", "secret")
+
+	fromEnv := load()
+
+	os.Clearenv()
+
+	fromFlag := load("--host", "example.com")
+	other := load("--host", "localhost")
+
+	assert.Len(t, fromEnv.Fingerprint(), 64)
+	assert.Equal(t, fromEnv.Fingerprint(), fromFlag.Fingerprint())
+	assert.NotEqual(t, fromEnv.Fingerprint(), other.Fingerprint())
+
+	os.Clearenv()
+}
+
+func TestReport_Redactor(t *testing.T) {
+	type config struct {
+		Host     string
+		Password string `secret:"true"`
+		Token    nest.SecretString
+		Database struct {
+			Password string `secret:"true"`
+		}
+	}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.AddSource(mapSource{
+		"Host":              "example.com",
+		"Password":          "hunter2",
+		"Token":             "token",
+		"Database.Password": "hunter2hunter2",
+	})
+
+	report, err := configurator.LoadWithReport(&config{})
+	require.NoError(t, err)
+
+	redactor := report.Redactor()
+
+	assert.Equal(t, "connecting to example.com with <hidden> and <hidden>", redactor.Redact("connecting to example.com with hunter2hunter2 and token"))
+	assert.Equal(t, "<hidden>", redactor.RedactField("database.password", "anything"))
+	assert.Equal(t, "host example.com", redactor.RedactField("host", "host example.com"))
+	assert.True(t, redactor.IsSecretKey("Token"))
+	assert.False(t, redactor.IsSecretKey("Host"))
+
+	assert.Equal(t, map[string]interface{}{
+		"Host":              "example.com",
+		"Password":          "<hidden>",
+		"Token":             "<hidden>",
+		"Database.Password": "<hidden>",
+	}, report.LogFields())
+}
+
+func TestReport_Redactor_CurrentValues(t *testing.T) {
+	type config struct {
+		Password *nest.Secret `ttl:"1ns"`
+		Token    nest.SecretString
+	}
+
+	source := mapSource{"Password": "hunter2", "Token": "token"}
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.AddSource(source)
+
+	report, err := configurator.LoadWithReport(&actual)
+	require.NoError(t, err)
+
+	redactor := report.Redactor()
+
+	assert.Equal(t, "<hidden> <hidden>", redactor.Redact("hunter2 token"))
+
+	// Rotated values are hidden
+	source["Password"] = "rotated"
+	time.Sleep(time.Millisecond)
+
+	_, err = actual.Password.Get()
+	require.NoError(t, err)
+
+	assert.Equal(t, "<hidden> <hidden>", redactor.Redact("rotated token"))
+
+	// Closed values are not retained
+	require.NoError(t, actual.Token.Close())
+
+	assert.Equal(t, "<hidden> token", redactor.Redact("rotated token"))
+}
+
+func TestReport_Recover(t *testing.T) {
+	type config struct {
+		Host     string `default:"localhost"`
+		Password string `default:"secret" secret:"true"`
+	}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+
+	report, err := configurator.LoadWithReport(&config{})
+	require.NoError(t, err)
+
+	var reported interface{}
+	var info nest.CrashInfo
+
+	assert.PanicsWithValue(t, "crash", func() {
+		defer report.Recover(func(recovered interface{}, crashInfo nest.CrashInfo) {
+			reported = recovered
+			info = crashInfo
+		})
+
+		panic("crash")
+	})
+
+	assert.Equal(t, "crash", reported)
+	assert.Equal(t, nest.CrashInfo{
+		Fingerprint: report.Fingerprint(),
+		Values:      map[string]string{"Host": "localhost", "Password": "<hidden>"},
+		Origins:     map[string]nest.Origin{"Host": nest.OriginDefault, "Password": nest.OriginDefault},
+	}, info)
+
+	assert.NotPanics(t, func() {
+		defer report.Recover(func(interface{}, nest.CrashInfo) {
+			t.Error("the reporter must not be called without a panic")
+		})
+	})
+}
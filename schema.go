@@ -0,0 +1,268 @@
+package nest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrSchemaNotBound is returned when resolving a schema which is not bound to a configuration struct.
+var ErrSchemaNotBound = errors.New("schema is not bound to a configuration struct")
+
+// Field describes a single configuration entry of a Schema.
+//
+// Fields can be modified before resolving the schema (eg. to rename flags or mark entries hidden).
+type Field struct {
+	// Key is the configuration key of the field (eg. Database.Host).
+	Key string `json:"key"`
+
+	// Aliases are former keys of the field, also accepted as flag and environment variable names.
+	Aliases []string `json:"aliases,omitempty"`
+
+	// Type is the name of the Go type of the field.
+	Type string `json:"type"`
+
+	// Flag is the name of the flag (without dashes) or empty if the field cannot be set from a flag.
+	Flag string `json:"flag,omitempty"`
+
+	// Env is the name of the environment variable (without the prefix)
+	// or empty if the field cannot be set from the environment.
+	Env string `json:"env,omitempty"`
+
+	// EnvAliases are additional environment variable names (without the prefix) read when Env is not set.
+	EnvAliases []string `json:"envAliases,omitempty"`
+
+	Default    string `json:"default,omitempty"`
+	HasDefault bool   `json:"hasDefault,omitempty"`
+
+	// Redacted reports that the default value was left out of the JSON representation (defaults of secret fields are never exported).
+	// Bind restores it from the struct the schema is bound to.
+	Redacted bool `json:"redacted,omitempty"`
+
+	Required bool     `json:"required,omitempty"`
+	Secret   bool     `json:"secret,omitempty"`
+	Choices  []string `json:"choices,omitempty"`
+	Usage    string   `json:"usage,omitempty"`
+
+	// Example is an illustrative value of the field (eg. a connection string).
+	Example string `json:"example,omitempty"`
+
+	// Section is the name of the help section the field is displayed in.
+	Section string `json:"section,omitempty"`
+
+	// Unit (eg. ms) and Format (eg. hostport) describe the value for documentation purposes.
+	Unit   string `json:"unit,omitempty"`
+	Format string `json:"format,omitempty"`
+
+	// Hidden fields are not displayed in the help output.
+	Hidden bool `json:"hidden,omitempty"`
+
+	def fieldDefinition
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// Default values of secret fields are left out and the field is marked as redacted.
+func (f Field) MarshalJSON() ([]byte, error) {
+	// Avoid infinite recursion
+	type field Field
+
+	if f.Secret && f.HasDefault {
+		f.Default = ""
+		f.Redacted = true
+	}
+
+	return json.Marshal(field(f))
+}
+
+// Schema is the configuration surface of a struct discovered by Parse.
+//
+// Schemas can be serialized to JSON, so that external tools can work with the configuration surface of a service.
+// A schema read from JSON has to be bound to a struct with Bind before resolving it.
+type Schema struct {
+	Fields []*Field `json:"fields"`
+
+	configurator *Configurator
+	target       reflect.Value
+}
+
+// Parse discovers the configuration fields of a struct without loading any values.
+//
+// The returned schema can be inspected and modified before calling Resolve.
+func (c *Configurator) Parse(config interface{}) (*Schema, error) {
+	elem, err := getTarget(config)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	definitions, err := c.getDefinitions(elem)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSchema(c, elem, definitions), nil
+}
+
+// Bind attaches the schema to a configuration struct, so that it can be resolved.
+//
+// It is mostly useful for schemas read from JSON: every field of the schema must be present in the struct.
+func (s *Schema) Bind(c *Configurator, config interface{}) error {
+	elem, err := getTarget(config)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	definitions, err := c.getDefinitions(elem)
+	if err != nil {
+		return err
+	}
+
+	defs := make(map[string]fieldDefinition, len(definitions))
+	for _, def := range definitions {
+		defs[def.key] = def
+	}
+
+	for _, field := range s.Fields {
+		def, ok := defs[field.Key]
+		if !ok {
+			return fmt.Errorf("schema field %s not found in configuration", field.Key)
+		}
+
+		field.def = def
+
+		// Redacted defaults are taken from the struct
+		if field.Redacted {
+			field.Default = def.defaultValue
+			field.Redacted = false
+		}
+	}
+
+	s.configurator = c
+	s.target = elem
+
+	return nil
+}
+
+// Field returns the field with the given key or nil if there is no such field.
+func (s *Schema) Field(key string) *Field {
+	for _, field := range s.Fields {
+		if field.Key == key {
+			return field
+		}
+	}
+
+	return nil
+}
+
+// Resolve loads the configuration values into the struct the schema was parsed from.
+//
+// The sources are consulted after the ones configured on the configurator.
+func (s *Schema) Resolve(sources ...Source) error {
+	return s.ResolveContext(context.Background(), sources...)
+}
+
+// ResolveContext is the same as Resolve, but it accepts a context which is passed to the sources.
+func (s *Schema) ResolveContext(ctx context.Context, sources ...Source) error {
+	c := s.configurator
+	if c == nil {
+		return ErrSchemaNotBound
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.name == "" {
+		c.name = c.args[0]
+	}
+
+	allSources := make([]prioritizedSource, 0, len(c.sources)+len(sources))
+	allSources = append(allSources, c.sources...)
+	allSources = append(allSources, withPriority(sources, PrioritySource)...)
+
+	_, err := c.load(ctx, s.target, s.definitions(), allSources)
+
+	return err
+}
+
+// newSchema creates a schema from definitions.
+func newSchema(c *Configurator, elem reflect.Value, definitions []fieldDefinition) *Schema {
+	schema := &Schema{
+		Fields: make([]*Field, len(definitions)),
+
+		configurator: c,
+		target:       elem,
+	}
+
+	for i, def := range definitions {
+		schema.Fields[i] = newField(def)
+	}
+
+	return schema
+}
+
+// definitions returns the field definitions of the schema.
+func (s *Schema) definitions() []fieldDefinition {
+	definitions := make([]fieldDefinition, len(s.Fields))
+	for i, field := range s.Fields {
+		definitions[i] = field.definition()
+	}
+
+	return definitions
+}
+
+// newField creates a field from a definition.
+func newField(def fieldDefinition) *Field {
+	return &Field{
+		Key:        def.key,
+		Aliases:    def.keyAliases,
+		Type:       def.field.Type().String(),
+		Flag:       def.flagAlias,
+		Env:        def.envAlias,
+		EnvAliases: def.envAliases,
+		Default:    def.defaultValue,
+		HasDefault: def.hasDefault,
+		Required:   def.required,
+		Secret:     def.secret,
+		Choices:    def.choices,
+		Usage:      def.usage,
+		Example:    def.example,
+		Section:    def.section,
+		Unit:       def.unit,
+		Format:     def.format,
+		Hidden:     def.hidden,
+
+		def: def,
+	}
+}
+
+// definition applies the modifications of the field to the original definition.
+func (f *Field) definition() fieldDefinition {
+	def := f.def
+
+	def.keyAliases = f.Aliases
+	def.hasFlag = f.Flag != ""
+	def.flagAlias = f.Flag
+	def.hasEnv = f.Env != ""
+	def.envAlias = f.Env
+	def.envAliases = f.EnvAliases
+	def.defaultValue = f.Default
+	def.hasDefault = f.HasDefault
+	def.required = f.Required
+	def.secret = f.Secret
+	def.choices = f.Choices
+	def.usage = f.Usage
+	def.example = f.Example
+	def.section = f.Section
+	def.unit = f.Unit
+	def.format = f.Format
+	def.hidden = f.Hidden
+
+	return def
+}
@@ -0,0 +1,199 @@
+package nest_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurator_Parse(t *testing.T) {
+	type config struct {
+		Host string `flag:"host" env:"" default:"localhost" usage:"Server host"`
+		Port int    `env:"" required:"true"`
+	}
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+
+	schema, err := configurator.Parse(&c)
+	require.NoError(t, err)
+	require.Len(t, schema.Fields, 2)
+
+	assert.Equal(t, "Host", schema.Fields[0].Key)
+	assert.Equal(t, "string", schema.Fields[0].Type)
+	assert.Equal(t, "host", schema.Fields[0].Flag)
+	assert.Equal(t, "HOST", schema.Fields[0].Env)
+	assert.Equal(t, "localhost", schema.Fields[0].Default)
+	assert.True(t, schema.Fields[0].HasDefault)
+	assert.Equal(t, "Server host", schema.Fields[0].Usage)
+
+	assert.Equal(t, "Port", schema.Fields[1].Key)
+	assert.Equal(t, "int", schema.Fields[1].Type)
+	assert.Empty(t, schema.Fields[1].Flag)
+	assert.True(t, schema.Fields[1].Required)
+
+	assert.Equal(t, schema.Fields[1], schema.Field("Port"))
+	assert.Nil(t, schema.Field("Missing"))
+}
+
+func TestConfigurator_Parse_NotStructPointer(t *testing.T) {
+	configurator := nest.NewConfigurator()
+
+	_, err := configurator.Parse(struct{}{})
+	assert.Equal(t, nest.ErrNotStructPointer, err)
+}
+
+func TestSchema_Resolve(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("OLD_PORT", "8080")
+
+	type config struct {
+		Host string `flag:"host" default:"localhost"`
+		Port int    `env:"port"`
+		Name string
+	}
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app", "--address", "example.com"})
+
+	schema, err := configurator.Parse(&c)
+	require.NoError(t, err)
+
+	schema.Field("Host").Flag = "address"
+	schema.Field("Port").EnvAliases = []string{"OLD_PORT"}
+
+	err = schema.Resolve(mapSource{"Name": "service"})
+	require.NoError(t, err)
+
+	assert.Equal(t, config{Host: "example.com", Port: 8080, Name: "service"}, c)
+
+	os.Clearenv()
+}
+
+func TestSchema_Resolve_Hidden(t *testing.T) {
+	type config struct {
+		Value  string `flag:"value"`
+		Secret string `flag:"internal"`
+	}
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetName("app")
+
+	schema, err := configurator.Parse(&c)
+	require.NoError(t, err)
+
+	schema.Field("Secret").Hidden = true
+
+	configurator.SetArgs([]string{"app", "--internal", "value"})
+
+	err = schema.Resolve()
+	require.NoError(t, err)
+	assert.Equal(t, "value", c.Secret)
+	assert.True(t, configurator.Flags().Lookup("internal").Hidden)
+}
+
+func TestSchema_JSON(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Level string `flag:"level" enum:"debug,info" default:"info" usage:"Log level"`
+		Port  int    `env:"port" required:"true"`
+	}
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+
+	schema, err := configurator.Parse(&c)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"fields":[{"key":"Level","type":"string","flag":"level","default":"info","hasDefault":true,"choices":["debug","info"],"usage":"Log level"},{"key":"Port","type":"int","env":"PORT","required":true}]}`, string(data))
+
+	var imported nest.Schema
+	err = json.Unmarshal(data, &imported)
+	require.NoError(t, err)
+
+	err = imported.Resolve()
+	assert.Equal(t, nest.ErrSchemaNotBound, err)
+
+	imported.Field("Level").Default = "debug"
+	imported.Field("Port").Required = false
+
+	configurator = nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+
+	err = imported.Bind(configurator, &c)
+	require.NoError(t, err)
+
+	err = imported.Resolve()
+	require.NoError(t, err)
+	assert.Equal(t, config{Level: "debug"}, c)
+}
+
+func TestSchema_Bind_UnknownField(t *testing.T) {
+	type config struct {
+		Value string
+	}
+
+	schema := nest.Schema{
+		Fields: []*nest.Field{{Key: "Missing"}},
+	}
+
+	err := schema.Bind(nest.NewConfigurator(), &config{})
+	assert.EqualError(t, err, "schema field Missing not found in configuration")
+}
+
+func TestSchema_JSON_SecretDefault(t *testing.T) {
+	type config struct {
+		Password string `default:"s3cr3t" secret:"true"`
+	}
+
+	schema, err := nest.NewConfigurator().Parse(&config{})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cr3t")
+	assert.NotContains(t, string(data), "hidden")
+	assert.Equal(t, "s3cr3t", schema.Field("Password").Default)
+}
+
+func TestSchema_JSON_SecretDefaultRoundTrip(t *testing.T) {
+	type config struct {
+		Password string `default:"s3cr3t" secret:"true"`
+	}
+
+	schema, err := nest.NewConfigurator().Parse(&config{})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"fields":[{"key":"Password","type":"string","hasDefault":true,"redacted":true,"secret":true}]}`, string(data))
+
+	var imported nest.Schema
+	err = json.Unmarshal(data, &imported)
+	require.NoError(t, err)
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+
+	err = imported.Bind(configurator, &c)
+	require.NoError(t, err)
+
+	err = imported.Resolve()
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", c.Password)
+}
@@ -0,0 +1,268 @@
+package nest
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// hiddenValue is displayed instead of sensitive values.
+const hiddenValue = "<hidden>"
+
+// secretType is the reflected type of Secret.
+var secretType = reflect.TypeOf(Secret{})
+
+// Secret is a lazy handle for a sensitive string value.
+//
+// When the field has a TTL (configured with the ttl tag) the value is re-fetched from the sources
+// once it expires, so that rotated credentials (eg. database passwords) are picked up without reloading the whole configuration.
+//
+// The value is hidden from every fmt verb and from JSON and text marshaling. Copies of a Secret share the same value.
+type Secret struct {
+	state *secretState
+}
+
+// secretState holds the value of a secret and the details of re-fetching it.
+type secretState struct {
+	value     string
+	ttl       time.Duration
+	expiresAt time.Time
+	fetch     func() (string, bool, error)
+
+	mu sync.Mutex
+}
+
+// NewSecret returns a Secret holding a static value.
+func NewSecret(value string) *Secret {
+	return &Secret{
+		state: &secretState{value: value},
+	}
+}
+
+// getState returns the state of the secret, allocating it for zero values.
+func (s *Secret) getState() *secretState {
+	if s.state == nil {
+		s.state = &secretState{}
+	}
+
+	return s.state
+}
+
+// Decode implements the Decoder interface.
+func (s *Secret) Decode(value string) error {
+	state := s.getState()
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.value = value
+
+	return nil
+}
+
+// Get returns the secret value, re-fetching it first if it has expired.
+func (s *Secret) Get() (string, error) {
+	state := s.state
+	if state == nil {
+		return "", nil
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if state.fetch != nil && state.ttl > 0 && !time.Now().Before(state.expiresAt) {
+		value, ok, err := state.fetch()
+		if err != nil {
+			return "", err
+		}
+
+		// Keep the current value if the sources do not know about the secret (anymore)
+		if ok {
+			state.value = value
+		}
+
+		state.expiresAt = time.Now().Add(state.ttl)
+	}
+
+	return state.value, nil
+}
+
+// current returns the current secret value without re-fetching it.
+func (s *Secret) current() string {
+	state := s.state
+	if state == nil {
+		return ""
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	return state.value
+}
+
+// String implements the fmt.Stringer interface and hides the secret value.
+func (s Secret) String() string {
+	return hiddenValue
+}
+
+// GoString implements the fmt.GoStringer interface and hides the secret value.
+func (s Secret) GoString() string {
+	return hiddenValue
+}
+
+// Format implements the fmt.Formatter interface and hides the secret value for every verb.
+func (s Secret) Format(f fmt.State, verb rune) {
+	io.WriteString(f, hiddenValue)
+}
+
+// MarshalJSON implements the json.Marshaler interface and hides the secret value.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + hiddenValue + `"`), nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface and hides the secret value.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(hiddenValue), nil
+}
+
+// bind configures the fetch function and TTL of the secret.
+func (s *Secret) bind(ttl time.Duration, fetch func() (string, bool, error)) {
+	state := s.getState()
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.ttl = ttl
+	state.fetch = fetch
+	state.expiresAt = time.Now().Add(ttl)
+}
+
+// secretStringType is the reflected type of SecretString.
+var secretStringType = reflect.TypeOf(SecretString{})
+
+// SecretString holds a sensitive string value in a guarded buffer to reduce accidental leakage.
+//
+// The value is hidden from every fmt verb and from JSON and text marshaling,
+// it's kept in memory masked with a random key (so that it doesn't appear in core dumps as is)
+// and it's zeroed by Close. Fields of this type are treated as secret fields.
+//
+// Copies of a SecretString share the same buffer.
+type SecretString struct {
+	buf *secretBuffer
+}
+
+// secretBuffer holds a masked value.
+type secretBuffer struct {
+	masked []byte
+	key    []byte
+
+	mu sync.Mutex
+}
+
+// NewSecretString returns a SecretString holding a value.
+func NewSecretString(value string) SecretString {
+	var s SecretString
+	s.set([]byte(value))
+
+	return s
+}
+
+// Decode implements the Decoder interface.
+func (s *SecretString) Decode(value string) error {
+	s.set([]byte(value))
+
+	return nil
+}
+
+// set masks a value with a random key and stores it in a new buffer.
+func (s *SecretString) set(value []byte) {
+	key := make([]byte, len(value))
+
+	// The masking is best effort, the value is still zeroed on Close
+	rand.Read(key)
+
+	masked := make([]byte, len(value))
+	for i := range value {
+		masked[i] = value[i] ^ key[i]
+	}
+
+	s.buf = &secretBuffer{
+		masked: masked,
+		key:    key,
+	}
+}
+
+// Bytes returns a copy of the secret value, which can be zeroed by the caller after use.
+// It returns nil if the value is not set or the buffer is closed.
+func (s SecretString) Bytes() []byte {
+	if s.buf == nil {
+		return nil
+	}
+
+	s.buf.mu.Lock()
+	defer s.buf.mu.Unlock()
+
+	if s.buf.masked == nil {
+		return nil
+	}
+
+	value := make([]byte, len(s.buf.masked))
+	for i := range s.buf.masked {
+		value[i] = s.buf.masked[i] ^ s.buf.key[i]
+	}
+
+	return value
+}
+
+// Value returns the secret value.
+func (s SecretString) Value() string {
+	return string(s.Bytes())
+}
+
+// Close zeroes the buffer holding the secret value (in every copy of the SecretString).
+func (s SecretString) Close() error {
+	if s.buf == nil {
+		return nil
+	}
+
+	s.buf.mu.Lock()
+	defer s.buf.mu.Unlock()
+
+	for i := range s.buf.masked {
+		s.buf.masked[i] = 0
+		s.buf.key[i] = 0
+	}
+
+	s.buf.masked = nil
+	s.buf.key = nil
+
+	return nil
+}
+
+// String implements the fmt.Stringer interface and hides the secret value.
+func (s SecretString) String() string {
+	return hiddenValue
+}
+
+// GoString implements the fmt.GoStringer interface and hides the secret value.
+func (s SecretString) GoString() string {
+	return hiddenValue
+}
+
+// Format implements the fmt.Formatter interface and hides the secret value for every verb.
+func (s SecretString) Format(f fmt.State, verb rune) {
+	io.WriteString(f, hiddenValue)
+}
+
+// MarshalJSON implements the json.Marshaler interface and hides the secret value.
+func (s SecretString) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + hiddenValue + `"`), nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface and hides the secret value.
+func (s SecretString) MarshalText() ([]byte, error) {
+	return []byte(hiddenValue), nil
+}
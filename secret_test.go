@@ -0,0 +1,195 @@
+package nest_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecret(t *testing.T) {
+	secret := nest.NewSecret("secret")
+
+	value, err := secret.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "secret", value)
+	assert.Equal(t, "<hidden>", secret.String())
+}
+
+func TestSecret_Format(t *testing.T) {
+	type config struct {
+		Password nest.Secret
+	}
+
+	c := config{Password: *nest.NewSecret("hunter2")}
+
+	for _, format := range []string{"%s", "%v", "%+v", "%#v", "%q", "%x"} {
+		assert.NotContains(t, fmt.Sprintf(format, c), "hunter2", format)
+		assert.NotContains(t, fmt.Sprintf(format, c.Password), "hunter2", format)
+	}
+
+	assert.Equal(t, "{Password:<hidden>}", fmt.Sprintf("%+v", c))
+
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.Equal(t, `{"Password":"\u003chidden\u003e"}`, string(data))
+
+	value, err := c.Password.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", value)
+}
+
+func TestConfigurator_Load_Secret(t *testing.T) {
+	type config struct {
+		Password nest.Secret
+	}
+
+	source := mapSource{"Password": "password"}
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.AddSource(source)
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+
+	source["Password"] = "rotated"
+
+	value, err := actual.Password.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "password", value)
+}
+
+func TestConfigurator_Load_SecretRotation(t *testing.T) {
+	type config struct {
+		Password *nest.Secret `ttl:"1ns"`
+	}
+
+	source := mapSource{"Password": "password"}
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.AddSource(source)
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+
+	source["Password"] = "rotated"
+	time.Sleep(time.Millisecond)
+
+	value, err := actual.Password.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", value)
+}
+
+func TestConfigurator_Load_SecretRotationKeepsFlag(t *testing.T) {
+	type config struct {
+		Password *nest.Secret `flag:"" ttl:"1ns"`
+	}
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app", "--password", "fromflag"})
+	configurator.AddSource(mapSource{"Password": "fromsource"})
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+
+	time.Sleep(time.Millisecond)
+
+	value, err := actual.Password.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "fromflag", value)
+}
+
+func TestConfigurator_Load_SecretRotationDecrypt(t *testing.T) {
+	type config struct {
+		Password *nest.Secret `ttl:"1ns"`
+	}
+
+	source := mapSource{"Password": "kms:abc"}
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.SetDecryptor("kms", reverseDecryptor{})
+	configurator.AddSource(source)
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+
+	value, err := actual.Password.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "cba", value)
+
+	source["Password"] = "kms:rotated"
+	time.Sleep(time.Millisecond)
+
+	value, err = actual.Password.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "detator", value)
+}
+
+func TestConfigurator_Load_SecretInvalidTTL(t *testing.T) {
+	type config struct {
+		Password nest.Secret `ttl:"forever" default:"password"`
+	}
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+
+	err := configurator.Load(&actual)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid ttl for field Password")
+}
+
+func TestSecretString(t *testing.T) {
+	secret := nest.NewSecretString("secret")
+
+	assert.Equal(t, "secret", secret.Value())
+	assert.Equal(t, []byte("secret"), secret.Bytes())
+
+	for _, format := range []string{"%s", "%v", "%+v", "%#v", "%q", "%x"} {
+		assert.Equal(t, "<hidden>", fmt.Sprintf(format, secret), format)
+	}
+
+	assert.Equal(t, "{<hidden>}", fmt.Sprintf("%v", struct{ Password nest.SecretString }{secret}))
+
+	data, err := json.Marshal(map[string]interface{}{"password": secret})
+	require.NoError(t, err)
+	assert.Equal(t, `{"password":"\u003chidden\u003e"}`, string(data))
+
+	copied := secret
+
+	require.NoError(t, secret.Close())
+	assert.Equal(t, "", copied.Value())
+	assert.Nil(t, copied.Bytes())
+}
+
+func TestConfigurator_Load_SecretString(t *testing.T) {
+	type config struct {
+		Password nest.SecretString
+	}
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.AddSource(mapSource{"Password": "secret"})
+
+	report, err := configurator.LoadWithReport(&c)
+	require.NoError(t, err)
+
+	assert.Equal(t, "secret", c.Password.Value())
+	assert.True(t, report.Field("Password").Secret)
+	assert.Equal(t, "<hidden>", report.Field("Password").Value)
+}
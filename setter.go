@@ -0,0 +1,62 @@
+package nest
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FieldSetter is implemented by configuration structs with unexported fields (eg. encapsulated package configuration).
+//
+// Unexported fields with configuration tags (eg. env or default) are loaded into a temporary value,
+// which is passed to SetConfigField along with the name of the field once every value is loaded.
+// Other unexported fields are left untouched.
+type FieldSetter interface {
+	SetConfigField(name string, value interface{}) error
+}
+
+// fieldSetterType is the reflected type of FieldSetter.
+var fieldSetterType = reflect.TypeOf((*FieldSetter)(nil)).Elem()
+
+// getFieldSetter returns the function passing the value of a field to it's setter method (set by the setter tag)
+// or to the SetConfigField method of the struct, if any.
+func getFieldSetter(structRef reflect.Value, structField reflect.StructField, key string) (func(value reflect.Value) error, error) {
+	if !structRef.CanAddr() {
+		return nil, nil
+	}
+
+	ptr := structRef.Addr()
+
+	if name, ok := structField.Tag.Lookup(TagSetter); ok {
+		method := ptr.MethodByName(name)
+		if !method.IsValid() {
+			return nil, fmt.Errorf("setter %s of field %s not found on %s", name, key, ptr.Type())
+		}
+
+		typ := method.Type()
+		if typ.NumIn() != 1 || !structField.Type.AssignableTo(typ.In(0)) || typ.NumOut() > 1 || typ.NumOut() == 1 && typ.Out(0) != errorType {
+			return nil, fmt.Errorf("setter %s of field %s must have the signature func(%s) or func(%s) error", name, key, structField.Type, structField.Type)
+		}
+
+		return func(value reflect.Value) error {
+			out := method.Call([]reflect.Value{value})
+			if len(out) == 1 && !out[0].IsNil() {
+				return out[0].Interface().(error)
+			}
+
+			return nil
+		}, nil
+	}
+
+	if isExported(structField.Name) || !ptr.Type().Implements(fieldSetterType) || len(configurationTagKeys(structField.Tag)) == 0 {
+		return nil, nil
+	}
+
+	setter := ptr.Interface().(FieldSetter)
+
+	return func(value reflect.Value) error {
+		return setter.SetConfigField(structField.Name, value.Interface())
+	}, nil
+}
+
+// errorType is the reflected type of error.
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
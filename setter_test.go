@@ -0,0 +1,92 @@
+package nest_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type encapsulatedConfig struct {
+	host string `env:"" default:"localhost"`
+	port int    `env:"" setter:"SetPort"`
+	name string
+}
+
+func (c *encapsulatedConfig) SetPort(port int) error {
+	if port < 1 {
+		return errors.New("port must be positive")
+	}
+
+	c.port = port
+
+	return nil
+}
+
+func (c *encapsulatedConfig) SetConfigField(name string, value interface{}) error {
+	switch name {
+	case "host":
+		c.host = value.(string)
+
+	default:
+		return fmt.Errorf("unknown field %s", name)
+	}
+
+	return nil
+}
+
+func TestConfigurator_Load_Setters(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("PORT", "8080")
+
+	actual := encapsulatedConfig{name: "app"}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+
+	report, err := configurator.LoadWithReport(&actual)
+	require.NoError(t, err)
+
+	assert.Equal(t, encapsulatedConfig{host: "localhost", port: 8080, name: "app"}, actual)
+	assert.Equal(t, nest.OriginEnv, report.Field("port").Origin)
+
+	os.Setenv("PORT", "0")
+
+	err = configurator.Load(&encapsulatedConfig{})
+	assert.EqualError(t, err, "failed to set port: port must be positive")
+
+	os.Clearenv()
+}
+
+func TestConfigurator_Load_SetterExportedField(t *testing.T) {
+	os.Clearenv()
+
+	actual := struct {
+		Port int `default:"8080" setter:"SetPort"`
+	}{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+
+	err := configurator.Load(&actual)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "setter SetPort of field Port not found")
+}
+
+type invalidSetterConfig struct {
+	port int `setter:"SetPort"`
+}
+
+func (c *invalidSetterConfig) SetPort(port string) {}
+
+func TestConfigurator_Load_InvalidSetter(t *testing.T) {
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+
+	err := configurator.Load(&invalidSetterConfig{})
+	assert.EqualError(t, err, "setter SetPort of field port must have the signature func(int) or func(int) error")
+}
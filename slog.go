@@ -0,0 +1,80 @@
+//go:build go1.21
+// +build go1.21
+
+package nest
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SetSlogLogger sets a structured logger recording the resolution of every field at debug level during Load
+// (key, origin, flag or environment variable name and the value with secrets hidden),
+// followed by a summary record with the time spent loading the configuration.
+//
+// Passing nil disables logging.
+func (c *Configurator) SetSlogLogger(logger *slog.Logger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if logger == nil {
+		c.logLoad = nil
+
+		return
+	}
+
+	c.logLoad = func(ctx context.Context, report *Report, err error) {
+		if !logger.Enabled(ctx, slog.LevelDebug) {
+			return
+		}
+
+		// Values are only reported once every field is loaded
+		if err != nil {
+			logger.LogAttrs(ctx, slog.LevelDebug, "configuration load failed", slog.Duration("duration", report.Duration), slog.String("error", err.Error()))
+
+			return
+		}
+
+		for _, field := range report.Fields {
+			attrs := []slog.Attr{
+				slog.String("key", field.Key),
+				slog.String("source", string(field.Origin)),
+			}
+
+			if field.Name != "" {
+				attrs = append(attrs, slog.String("name", field.Name))
+			}
+
+			attrs = append(attrs, slog.String("value", field.Value))
+
+			logger.LogAttrs(ctx, slog.LevelDebug, "configuration value resolved", attrs...)
+		}
+
+		logger.LogAttrs(ctx, slog.LevelDebug, "configuration loaded", slog.Int("fields", len(report.Fields)), slog.Duration("duration", report.Duration))
+	}
+}
+
+// SetSlogLogger calls the function with the same name on the global configurator instance.
+func SetSlogLogger(logger *slog.Logger) {
+	c.SetSlogLogger(logger)
+}
+
+// ReplaceAttr hides secret values in log records.
+// It can be used as the ReplaceAttr function of slog.HandlerOptions:
+//
+//	slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{ReplaceAttr: report.Redactor().ReplaceAttr})
+//
+// String attributes named after secret fields are hidden entirely, secret values are replaced in every other string.
+func (r *Redactor) ReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, r.RedactField(a.Key, a.Value.String()))
+
+	case slog.KindAny:
+		if r.IsSecretKey(a.Key) {
+			return slog.String(a.Key, hiddenValue)
+		}
+	}
+
+	return a
+}
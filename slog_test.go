@@ -0,0 +1,97 @@
+//go:build go1.21
+// +build go1.21
+
+package nest_test
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurator_SetSlogLogger(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("HOST", "example.com")
+
+	type config struct {
+		Host     string `env:"host"`
+		Password string `flag:"password" secret:"true"`
+		Port     int    `default:"8080"`
+	}
+
+	var buf bytes.Buffer
+
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "duration" {
+				return slog.Attr{}
+			}
+
+			return a
+		},
+	}))
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app", "--password", "secret"})
+	configurator.SetSlogLogger(logger)
+
+	err := configurator.Load(&config{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		`level=DEBUG msg="configuration value resolved" key=Host source=env name=HOST value=example.com`,
+		`level=DEBUG msg="configuration value resolved" key=Password source=flag name=--password value=<hidden>`,
+		`level=DEBUG msg="configuration value resolved" key=Port source=default value=8080`,
+		`level=DEBUG msg="configuration loaded" fields=3`,
+	}, strings.Split(strings.TrimSpace(buf.String()), "\n"))
+
+	buf.Reset()
+
+	configurator.SetArgs([]string{"app", "--unknown"})
+
+	err = configurator.Load(&config{})
+	require.Error(t, err)
+
+	assert.Equal(t, `level=DEBUG msg="configuration load failed" error="unknown flag: --unknown"`+"\n", buf.String())
+
+	os.Clearenv()
+}
+
+func TestRedactor_ReplaceAttr(t *testing.T) {
+	type config struct {
+		Host     string
+		Password string `secret:"true"`
+	}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.AddSource(mapSource{"Host": "example.com", "Password": "hunter2"})
+
+	report, err := configurator.LoadWithReport(&config{})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	redactor := report.Redactor()
+
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+
+			return redactor.ReplaceAttr(groups, a)
+		},
+	}))
+
+	logger.Info("connecting with hunter2", "host", "example.com", "password", "anything", "dsn", "user:hunter2@example.com")
+
+	assert.Equal(t, `level=INFO msg="connecting with <hidden>" host=example.com password=<hidden> dsn=user:<hidden>@example.com`+"\n", buf.String())
+}
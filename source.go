@@ -0,0 +1,347 @@
+package nest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Source is implemented by configuration backends (eg. Vault, SSM) providing values by configuration key.
+//
+// Values returned by sources take precedence over defaults,
+// but environment variables and flags can still override them.
+type Source interface {
+	// Lookup returns the value stored under the given key.
+	// The boolean return value reports whether the key is present in the source.
+	Lookup(key string) (string, bool, error)
+}
+
+// ContextSource is implemented by sources supporting cancellation (eg. network backed sources).
+type ContextSource interface {
+	Source
+
+	// LookupContext is the same as Lookup, but it accepts a context.
+	LookupContext(ctx context.Context, key string) (string, bool, error)
+}
+
+// Priorities of the built-in value origins and the default priority of sources (see AddSourceWithPriority).
+const (
+	PriorityDefault  = 0
+	PrioritySource   = 100
+	PriorityEnv      = 200
+	PriorityFlag     = 300
+	PriorityOverride = 400
+)
+
+// prioritizedSource is a source along with it's priority.
+type prioritizedSource struct {
+	Source
+
+	priority int
+}
+
+// withPriority returns the sources with the given priority.
+func withPriority(sources []Source, priority int) []prioritizedSource {
+	prioritized := make([]prioritizedSource, len(sources))
+	for i, source := range sources {
+		prioritized[i] = prioritizedSource{Source: source, priority: priority}
+	}
+
+	return prioritized
+}
+
+// sortSources orders sources by priority (keeping the order sources with the same priority were added in)
+// and splits them into the ones below environment variables, above environment variables and above flags.
+func (c *Configurator) sortSources(sources []prioritizedSource) (below []Source, aboveEnv []Source, aboveFlag []Source) {
+	sorted := make([]prioritizedSource, len(sources))
+	copy(sorted, sources)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].priority > sorted[j].priority
+	})
+
+	for _, source := range sorted {
+		switch {
+		case source.priority > PriorityFlag:
+			aboveFlag = append(aboveFlag, source.Source)
+
+		case source.priority > PriorityEnv:
+			aboveEnv = append(aboveEnv, source.Source)
+
+		default:
+			below = append(below, source.Source)
+		}
+	}
+
+	return below, aboveEnv, aboveFlag
+}
+
+// sourcesByPriority returns every source ordered by priority.
+func (c *Configurator) sourcesByPriority(sources []prioritizedSource) []Source {
+	below, aboveEnv, aboveFlag := c.sortSources(sources)
+
+	return append(append(aboveFlag, aboveEnv...), below...)
+}
+
+// SourceChecker is implemented by sources able to verify that their backend is available
+// (eg. a file is readable or a server is reachable) without looking up any value.
+type SourceChecker interface {
+	// CheckSource returns an error if the source is not available.
+	CheckSource(ctx context.Context) error
+}
+
+// SourceFlusher is implemented by sources buffering state (eg. a cache) which should be persisted
+// once at the end of a load instead of after every lookup.
+type SourceFlusher interface {
+	// FlushSource persists the buffered state.
+	FlushSource() error
+}
+
+// flushSources flushes every source implementing SourceFlusher and returns the errors (if any).
+func flushSources(sources []Source) []error {
+	var errs []error
+
+	for _, source := range sources {
+		if flusher, ok := source.(SourceFlusher); ok {
+			if err := flusher.FlushSource(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+// SourceStatus is the health of a source reported by CheckSources.
+type SourceStatus struct {
+	Source Source
+
+	// Checked reports whether the source implements SourceChecker (sources which don't are not checked).
+	Checked bool
+
+	// Err is the error returned by the check (if any).
+	Err error
+
+	// Duration is the time spent checking the source.
+	Duration time.Duration
+}
+
+// CheckSources checks the health of every configured source without loading the configuration,
+// so that readiness probes can verify the configuration infrastructure.
+//
+// The statuses are returned in the order the sources were added (followed by the configuration file, if any)
+// along with the errors of the failing checks (if any).
+func (c *Configurator) CheckSources(ctx context.Context) ([]SourceStatus, error) {
+	c.mu.Lock()
+	sources := c.getSources()
+	c.mu.Unlock()
+
+	statuses := make([]SourceStatus, len(sources))
+
+	var errs Errors
+
+	for i, prioritized := range sources {
+		source := prioritized.Source
+		statuses[i].Source = source
+
+		checker, ok := source.(SourceChecker)
+		if !ok {
+			continue
+		}
+
+		start := time.Now()
+		err := checker.CheckSource(ctx)
+
+		statuses[i].Checked = true
+		statuses[i].Err = err
+		statuses[i].Duration = time.Since(start)
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("source %d (%T) is unavailable: %v", i, source, err))
+		}
+	}
+
+	if len(errs) == 1 {
+		return statuses, errs[0]
+	} else if len(errs) > 1 {
+		return statuses, errs
+	}
+
+	return statuses, nil
+}
+
+// sourceValue is the result of looking up a key in the sources.
+type sourceValue struct {
+	value string
+	found bool
+
+	// Elements of list values or entries of object values (if the source keeps them, see structuredSource)
+	structured interface{}
+}
+
+// structuredSource is implemented by sources keeping the structure of list and object values (eg. FileSource),
+// so that they can be loaded into slice and map fields without being joined into a single string first.
+type structuredSource interface {
+	// lookupStructured returns the elements ([]string) of a list or the entries (map[string]string) of an object.
+	lookupStructured(key string) (interface{}, bool)
+}
+
+// lookupSources looks up a key in a list of sources and returns the first value found.
+func lookupSources(ctx context.Context, sources []Source, key string) (string, bool, error) {
+	value, err := lookupSourceValue(ctx, sources, key)
+
+	return value.value, value.found, err
+}
+
+// lookupSourceValue is the same as lookupSources, but it also returns the structure of the value (if any).
+func lookupSourceValue(ctx context.Context, sources []Source, key string) (sourceValue, error) {
+	for _, source := range sources {
+		if err := ctx.Err(); err != nil {
+			return sourceValue{}, err
+		}
+
+		var value string
+		var ok bool
+		var err error
+
+		if s, isContextSource := source.(ContextSource); isContextSource {
+			value, ok, err = s.LookupContext(ctx, key)
+		} else {
+			value, ok, err = source.Lookup(key)
+		}
+
+		if err != nil {
+			return sourceValue{}, err
+		}
+
+		if ok {
+			result := sourceValue{
+				value: value,
+				found: true,
+			}
+
+			if s, isStructured := source.(structuredSource); isStructured {
+				result.structured, _ = s.lookupStructured(key)
+			}
+
+			return result, nil
+		}
+	}
+
+	return sourceValue{}, nil
+}
+
+// lookupInterruptedError is returned by lookupAllSources when the context is done before every key is looked up.
+type lookupInterruptedError struct {
+	err error
+
+	// Indexes of the keys still being looked up
+	pending []int
+}
+
+// Error implements the error interface.
+func (e *lookupInterruptedError) Error() string {
+	return e.err.Error()
+}
+
+// lookupAllSources looks up a list of keys in the sources using a bounded number of concurrent workers.
+// Lookup errors are aggregated and returned at once.
+//
+// When the context is done, it returns without waiting for lookups stuck in sources ignoring the context.
+func lookupAllSources(ctx context.Context, sources []Source, keys []string, concurrency int) ([]sourceValue, error) {
+	values := make([]sourceValue, len(keys))
+
+	if len(sources) == 0 {
+		return values, nil
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	errs := make([]error, len(keys))
+	jobs := make(chan int)
+
+	var mu sync.Mutex
+	done := make([]bool, len(keys))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < concurrency && i < len(keys); i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := range jobs {
+				value, err := lookupSourceValue(ctx, sources, keys[j])
+
+				mu.Lock()
+
+				if err != nil {
+					errs[j] = fmt.Errorf("failed to look up %s: %v", keys[j], err)
+				} else {
+					values[j] = value
+				}
+
+				done[j] = true
+
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i := range keys {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+
+	close(jobs)
+
+	finished := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-ctx.Done():
+	}
+
+	if err := ctx.Err(); err != nil {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var pending []int
+		for i := range keys {
+			if !done[i] {
+				pending = append(pending, i)
+			}
+		}
+
+		return nil, &lookupInterruptedError{err: err, pending: pending}
+	}
+
+	var lookupErrs Errors
+	for _, err := range errs {
+		if err != nil {
+			lookupErrs = append(lookupErrs, err)
+		}
+	}
+
+	if len(lookupErrs) == 1 {
+		return nil, lookupErrs[0]
+	} else if len(lookupErrs) > 1 {
+		return nil, lookupErrs
+	}
+
+	return values, nil
+}
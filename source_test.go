@@ -0,0 +1,378 @@
+package nest_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapSource is a Source backed by a map.
+type mapSource map[string]string
+
+func (s mapSource) Lookup(key string) (string, bool, error) {
+	value, ok := s[key]
+
+	return value, ok, nil
+}
+
+// failingSource is a Source that always returns an error.
+type failingSource struct{}
+
+func (failingSource) Lookup(key string) (string, bool, error) {
+	return "", false, errors.New("source unavailable")
+}
+
+func TestConfigurator_Load_Source(t *testing.T) {
+	type subconfig struct {
+		Value string
+	}
+
+	type config struct {
+		Value   string
+		Sconfig subconfig
+	}
+
+	expected := config{
+		Value: "value",
+		Sconfig: subconfig{
+			Value: "subvalue",
+		},
+	}
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.AddSource(mapSource{"Value": "value"})
+	configurator.AddSource(mapSource{"Value": "other", "Sconfig.Value": "subvalue"})
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestConfigurator_Load_SourcePrecedence(t *testing.T) {
+	type config struct {
+		Env     string `env:"" default:"default"`
+		Source  string `env:"" default:"default"`
+		Default string `default:"default"`
+	}
+
+	expected := config{
+		Env:     "env",
+		Source:  "source",
+		Default: "default",
+	}
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.AddSource(mapSource{"Env": "source", "Source": "source"})
+
+	os.Clearenv()
+	os.Setenv("ENV", "env")
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	os.Clearenv()
+}
+
+func TestConfigurator_Load_SourcePriority(t *testing.T) {
+	type config struct {
+		Flag   string `env:"" flag:""`
+		Env    string `env:""`
+		Source string
+	}
+
+	expected := config{
+		Flag:   "flag",
+		Env:    "remote",
+		Source: "remote",
+	}
+	actual := config{}
+
+	local := mapSource{"Flag": "local", "Env": "local", "Source": "local"}
+	remote := mapSource{"Flag": "remote", "Env": "remote", "Source": "remote"}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app", "--flag", "flag"})
+	configurator.AddSource(local)
+	configurator.AddSourceWithPriority(remote, nest.PriorityEnv+1)
+
+	os.Clearenv()
+	os.Setenv("FLAG", "env")
+	os.Setenv("ENV", "env")
+
+	report, err := configurator.LoadWithReport(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, nest.OriginFlag, report.Field("Flag").Origin)
+	assert.Equal(t, nest.OriginSource, report.Field("Env").Origin)
+
+	os.Clearenv()
+}
+
+func TestConfigurator_Load_SourcePriorityAboveFlags(t *testing.T) {
+	type config struct {
+		Value string `flag:""`
+	}
+
+	actual := config{}
+
+	remote := mapSource{"Value": "remote"}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app", "--value", "flag"})
+	configurator.AddSourceWithPriority(remote, nest.PriorityFlag+1)
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, "remote", actual.Value)
+}
+
+func TestConfigurator_Load_SourcePriorityOrder(t *testing.T) {
+	type config struct {
+		Value string
+	}
+
+	actual := config{}
+
+	first := mapSource{"Value": "first"}
+	second := mapSource{"Value": "second"}
+
+	configurator := nest.NewConfigurator()
+	configurator.AddSource(first)
+	configurator.AddSourceWithPriority(second, nest.PrioritySource+1)
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, "second", actual.Value)
+}
+
+// structSource is a source of an uncomparable struct type.
+type structSource struct {
+	values map[string]string
+}
+
+func (s structSource) Lookup(key string) (string, bool, error) {
+	value, ok := s.values[key]
+
+	return value, ok, nil
+}
+
+func TestConfigurator_Load_SourcePriorityUncomparable(t *testing.T) {
+	type config struct {
+		Value string
+	}
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.AddSource(structSource{map[string]string{"Value": "first"}})
+	configurator.AddSourceWithPriority(structSource{map[string]string{"Value": "second"}}, nest.PrioritySource+1)
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, "second", actual.Value)
+}
+
+func TestSchema_Resolve_SourcePriority(t *testing.T) {
+	type config struct {
+		Value string
+	}
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.AddSource(mapSource{"Value": "registered"})
+	configurator.AddSourceWithPriority(mapSource{"Value": "priority"}, nest.PrioritySource+1)
+
+	schema, err := configurator.Parse(&actual)
+	require.NoError(t, err)
+
+	err = schema.Resolve(mapSource{"Value": "extra"})
+	require.NoError(t, err)
+	assert.Equal(t, "priority", actual.Value)
+}
+
+func TestConfigurator_Load_SourceError(t *testing.T) {
+	type config struct {
+		Value string
+	}
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.AddSource(failingSource{})
+
+	err := configurator.Load(&actual)
+	require.Error(t, err)
+	assert.EqualError(t, err, "failed to look up Value: source unavailable")
+}
+
+// concurrentSource is a ContextSource tracking the maximum number of concurrent lookups.
+type concurrentSource struct {
+	mu      sync.Mutex
+	current int
+	max     int
+}
+
+func (s *concurrentSource) Lookup(key string) (string, bool, error) {
+	return s.LookupContext(context.Background(), key)
+}
+
+func (s *concurrentSource) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	s.current++
+	if s.current > s.max {
+		s.max = s.current
+	}
+	s.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	s.mu.Lock()
+	s.current--
+	s.mu.Unlock()
+
+	return key, true, nil
+}
+
+func TestConfigurator_LoadContext_SourceConcurrency(t *testing.T) {
+	type config struct {
+		Value1 string
+		Value2 string
+		Value3 string
+		Value4 string
+		Value5 string
+	}
+
+	expected := config{
+		Value1: "Value1",
+		Value2: "Value2",
+		Value3: "Value3",
+		Value4: "Value4",
+		Value5: "Value5",
+	}
+	actual := config{}
+
+	source := &concurrentSource{}
+
+	configurator := nest.NewConfigurator()
+	configurator.AddSource(source)
+	configurator.SetSourceConcurrency(2)
+
+	err := configurator.LoadContext(context.Background(), &actual)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, 2, source.max)
+}
+
+func TestConfigurator_LoadContext_SourceErrors(t *testing.T) {
+	type config struct {
+		Value      string
+		OtherValue string
+	}
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.AddSource(failingSource{})
+	configurator.SetSourceConcurrency(2)
+
+	err := configurator.LoadContext(context.Background(), &actual)
+	require.Error(t, err)
+	assert.IsType(t, nest.Errors{}, err)
+	assert.EqualError(t, err, "failed to look up Value: source unavailable; failed to look up OtherValue: source unavailable")
+}
+
+func TestConfigurator_LoadContext_Canceled(t *testing.T) {
+	type config struct {
+		Value string
+	}
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.AddSource(mapSource{"Value": "value"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := configurator.LoadContext(ctx, &actual)
+	require.Error(t, err)
+	assert.Equal(t, context.Canceled, err)
+}
+
+// stallingSource is a Source blocking lookups of the Remote key until it's released.
+type stallingSource chan struct{}
+
+func (s stallingSource) Lookup(key string) (string, bool, error) {
+	if key == "Remote" {
+		<-s
+	}
+
+	return "", false, nil
+}
+
+func TestConfigurator_Load_LoadTimeout(t *testing.T) {
+	type config struct {
+		Local  string `default:"local"`
+		Remote string
+	}
+
+	source := make(stallingSource)
+	defer close(source)
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.AddSource(source)
+	configurator.SetSourceConcurrency(2)
+	configurator.SetLoadTimeout(20 * time.Millisecond)
+
+	err := configurator.Load(&config{})
+	require.Error(t, err)
+
+	assert.True(t, nest.Is(err, nest.ErrLoadTimeout))
+	assert.EqualError(t, err, "configuration loading timed out after 20ms, unresolved fields: Remote")
+
+	var timeoutErr *nest.LoadTimeoutError
+	require.True(t, nest.As(err, &timeoutErr))
+	assert.Equal(t, []string{"Remote"}, timeoutErr.Unresolved)
+}
+
+func TestConfigurator_CheckSources(t *testing.T) {
+	path, cleanup := newConfigFile(t, "config.json", `{"host": "example.com"}`)
+	defer cleanup()
+
+	missing := nest.NewFileSource(filepath.Join(filepath.Dir(path), "missing.json"))
+
+	configurator := nest.NewConfigurator()
+	configurator.AddSource(nest.NewFileSource(path))
+	configurator.AddSource(mapSource{})
+	configurator.AddSource(missing)
+
+	statuses, err := configurator.CheckSources(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "source 2 (*nest.FileSource) is unavailable")
+
+	require.Len(t, statuses, 3)
+
+	assert.True(t, statuses[0].Checked)
+	assert.NoError(t, statuses[0].Err)
+
+	assert.False(t, statuses[1].Checked)
+	assert.Equal(t, mapSource{}, statuses[1].Source)
+
+	assert.True(t, statuses[2].Checked)
+	assert.Error(t, statuses[2].Err)
+	assert.Equal(t, missing, statuses[2].Source)
+}
@@ -0,0 +1,117 @@
+package sqlsource_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+)
+
+// settingsDriver is a minimal database driver serving a settings table from memory.
+type settingsDriver struct {
+	settings map[string]interface{}
+	mu       sync.Mutex
+}
+
+func (d *settingsDriver) set(key string, value interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.settings[key] = value
+}
+
+func (d *settingsDriver) remove(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.settings, key)
+}
+
+func (d *settingsDriver) Open(name string) (driver.Conn, error) {
+	return &settingsConn{driver: d}, nil
+}
+
+type settingsConn struct {
+	driver *settingsDriver
+}
+
+func (c *settingsConn) Prepare(query string) (driver.Stmt, error) {
+	return &settingsStmt{driver: c.driver}, nil
+}
+
+func (c *settingsConn) Close() error {
+	return nil
+}
+
+func (c *settingsConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type settingsStmt struct {
+	driver *settingsDriver
+}
+
+func (s *settingsStmt) Close() error {
+	return nil
+}
+
+func (s *settingsStmt) NumInput() int {
+	return 1
+}
+
+func (s *settingsStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("exec is not supported")
+}
+
+func (s *settingsStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.driver.mu.Lock()
+	defer s.driver.mu.Unlock()
+
+	key, _ := args[0].(string)
+	if key == "broken" {
+		return nil, errors.New("connection lost")
+	}
+
+	rows := &settingsRows{}
+
+	if value, ok := s.driver.settings[key]; ok {
+		rows.values = []interface{}{value}
+	}
+
+	return rows, nil
+}
+
+type settingsRows struct {
+	values []interface{}
+}
+
+func (r *settingsRows) Columns() []string {
+	return []string{"value"}
+}
+
+func (r *settingsRows) Close() error {
+	return nil
+}
+
+func (r *settingsRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+
+	dest[0] = r.values[0]
+	r.values = r.values[1:]
+
+	return nil
+}
+
+var testDriver = &settingsDriver{
+	settings: map[string]interface{}{
+		"Database.Host": "db.example.com",
+		"Nullable":      nil,
+	},
+}
+
+func init() {
+	sql.Register("settings", testDriver)
+}
@@ -0,0 +1,111 @@
+// Package sqlsource implements a source reading key-value settings from a SQL table.
+package sqlsource
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// DefaultQuery is the query used for looking up a value by default.
+// It expects a settings table with name and value columns.
+const DefaultQuery = "SELECT value FROM settings WHERE name = ?"
+
+// Event is a change of a watched value.
+type Event struct {
+	Key     string
+	Value   string
+	Deleted bool
+}
+
+// Source looks up values from a SQL table.
+type Source struct {
+	db    *sql.DB
+	query string
+}
+
+// NewSource returns a new Source using DefaultQuery.
+func NewSource(db *sql.DB) *Source {
+	return &Source{
+		db:    db,
+		query: DefaultQuery,
+	}
+}
+
+// SetQuery sets the query used for looking up a value.
+// The query receives the key as it's only argument and must return a single column (eg. "SELECT value FROM config WHERE key = $1").
+func (s *Source) SetQuery(query string) {
+	s.query = query
+}
+
+// Lookup implements the nest.Source interface.
+func (s *Source) Lookup(key string) (string, bool, error) {
+	return s.LookupContext(context.Background(), key)
+}
+
+// LookupContext implements the nest.ContextSource interface.
+// NULL values are treated as missing.
+func (s *Source) LookupContext(ctx context.Context, key string) (string, bool, error) {
+	var value sql.NullString
+
+	err := s.db.QueryRowContext(ctx, s.query, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+
+	return value.String, value.Valid, nil
+}
+
+// CheckSource implements the nest.SourceChecker interface by pinging the database.
+func (s *Source) CheckSource(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// Watch polls the watched keys at the given interval and calls fn for every change.
+// It blocks until the context is cancelled or a lookup fails.
+func (s *Source) Watch(ctx context.Context, keys []string, interval time.Duration, fn func(event Event)) error {
+	values := make(map[string]sql.NullString, len(keys))
+
+	for _, key := range keys {
+		value, ok, err := s.LookupContext(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		values[key] = sql.NullString{String: value, Valid: ok}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-ticker.C:
+		}
+
+		for _, key := range keys {
+			value, ok, err := s.LookupContext(ctx, key)
+			if err != nil {
+				return err
+			}
+
+			previous := values[key]
+			if previous.Valid == ok && previous.String == value {
+				continue
+			}
+
+			values[key] = sql.NullString{String: value, Valid: ok}
+
+			fn(Event{
+				Key:     key,
+				Value:   value,
+				Deleted: !ok,
+			})
+		}
+	}
+}
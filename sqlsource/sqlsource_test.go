@@ -0,0 +1,89 @@
+package sqlsource_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/goph/nest"
+	"github.com/goph/nest/sqlsource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSource(t *testing.T) {
+	db, err := sql.Open("settings", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	type config struct {
+		Database struct {
+			Host string
+			Port int `default:"3306"`
+		}
+		Nullable string `default:"default"`
+	}
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.AddSource(sqlsource.NewSource(db))
+
+	err = configurator.Load(&c)
+	require.NoError(t, err)
+	assert.Equal(t, "db.example.com", c.Database.Host)
+	assert.Equal(t, 3306, c.Database.Port)
+	assert.Equal(t, "default", c.Nullable)
+}
+
+func TestSource_Error(t *testing.T) {
+	db, err := sql.Open("settings", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, _, err = sqlsource.NewSource(db).Lookup("broken")
+	assert.EqualError(t, err, "connection lost")
+}
+
+func TestSource_Watch(t *testing.T) {
+	db, err := sql.Open("settings", "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	testDriver.set("Watched", "value")
+	testDriver.set("Removed", "value")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan sqlsource.Event, 2)
+
+	done := make(chan error)
+	go func() {
+		done <- sqlsource.NewSource(db).Watch(ctx, []string{"Watched", "Removed"}, time.Millisecond, func(event sqlsource.Event) {
+			events <- event
+		})
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+
+	testDriver.set("Watched", "changed")
+	testDriver.remove("Removed")
+
+	received := map[string]sqlsource.Event{}
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-events:
+			received[event.Key] = event
+
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+
+	cancel()
+
+	assert.Equal(t, sqlsource.Event{Key: "Watched", Value: "changed"}, received["Watched"])
+	assert.Equal(t, sqlsource.Event{Key: "Removed", Deleted: true}, received["Removed"])
+	assert.Equal(t, context.Canceled, <-done)
+}
@@ -0,0 +1,117 @@
+package nest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// TagHandler is implemented by third-party packages defining custom struct tags (eg. `consul:"key"`).
+type TagHandler interface {
+	// HandleTag is called during the definition pass for every field having the tag.
+	// It receives the value of the tag and can modify the field (eg. mark it required).
+	HandleTag(field *Field, value string) error
+}
+
+// TagResolver is implemented by tag handlers providing values for the fields having the tag.
+//
+// Resolved values take precedence over sources, but environment variables and flags can still override them.
+type TagResolver interface {
+	TagHandler
+
+	// ResolveTag returns the value of a field based on the value of the tag.
+	// The boolean return value reports whether a value is present.
+	ResolveTag(ctx context.Context, value string) (string, bool, error)
+}
+
+var (
+	tagHandlers   = make(map[string]TagHandler)
+	tagHandlersMu sync.RWMutex
+)
+
+// RegisterTag makes a tag handler available for every configurator.
+// If RegisterTag is called twice with the same name, it panics.
+func RegisterTag(name string, handler TagHandler) {
+	tagHandlersMu.Lock()
+	defer tagHandlersMu.Unlock()
+
+	if handler == nil {
+		panic("nest: RegisterTag handler is nil")
+	}
+
+	if _, dup := tagHandlers[name]; dup {
+		panic("nest: RegisterTag called twice for tag " + name)
+	}
+
+	tagHandlers[name] = handler
+}
+
+// customTag is the value of a registered tag on a field.
+type customTag struct {
+	name    string
+	value   string
+	handler TagHandler
+}
+
+// getCustomTags returns the registered tags present in a struct tag sorted by name.
+func getCustomTags(lookup func(key string) (string, bool)) []customTag {
+	tagHandlersMu.RLock()
+	defer tagHandlersMu.RUnlock()
+
+	var tags []customTag
+
+	for name, handler := range tagHandlers {
+		if value, ok := lookup(name); ok {
+			tags = append(tags, customTag{
+				name:    name,
+				value:   value,
+				handler: handler,
+			})
+		}
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].name < tags[j].name
+	})
+
+	return tags
+}
+
+// handleCustomTags calls the tag handlers of a definition.
+func handleCustomTags(def fieldDefinition) (fieldDefinition, error) {
+	if len(def.customTags) == 0 {
+		return def, nil
+	}
+
+	field := newField(def)
+
+	for _, tag := range def.customTags {
+		if err := tag.handler.HandleTag(field, tag.value); err != nil {
+			return def, fmt.Errorf("failed to handle %s tag of %s: %v", tag.name, def.key, err)
+		}
+	}
+
+	return field.definition(), nil
+}
+
+// resolveCustomTags returns the first value resolved by the tag handlers of a definition.
+func resolveCustomTags(ctx context.Context, def fieldDefinition) (string, bool, error) {
+	for _, tag := range def.customTags {
+		resolver, ok := tag.handler.(TagResolver)
+		if !ok {
+			continue
+		}
+
+		value, ok, err := resolver.ResolveTag(ctx, tag.value)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to resolve %s tag of %s: %v", tag.name, def.key, err)
+		}
+
+		if ok {
+			return value, true, nil
+		}
+	}
+
+	return "", false, nil
+}
@@ -0,0 +1,92 @@
+package nest_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// usageTagHandler sets the usage of the field.
+type usageTagHandler struct{}
+
+func (usageTagHandler) HandleTag(field *nest.Field, value string) error {
+	if value == "" {
+		return errors.New("empty description")
+	}
+
+	field.Usage = value
+
+	return nil
+}
+
+// kvTagHandler resolves values from a key-value store.
+type kvTagHandler map[string]string
+
+func (kvTagHandler) HandleTag(field *nest.Field, value string) error {
+	return nil
+}
+
+func (h kvTagHandler) ResolveTag(ctx context.Context, value string) (string, bool, error) {
+	v, ok := h[value]
+
+	return v, ok, nil
+}
+
+func init() {
+	nest.RegisterTag("test_description", usageTagHandler{})
+	nest.RegisterTag("test_kv", kvTagHandler{"db/host": "db.example.com"})
+}
+
+func TestRegisterTag(t *testing.T) {
+	type config struct {
+		Value string `test_description:"My value"`
+	}
+
+	schema, err := nest.NewConfigurator().Parse(&config{})
+	require.NoError(t, err)
+	assert.Equal(t, "My value", schema.Field("Value").Usage)
+}
+
+func TestRegisterTag_HandlerError(t *testing.T) {
+	type config struct {
+		Value string `test_description:""`
+	}
+
+	_, err := nest.NewConfigurator().Parse(&config{})
+	assert.EqualError(t, err, "failed to handle test_description tag of Value: empty description")
+}
+
+func TestRegisterTag_Resolver(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Host  string `test_kv:"db/host" default:"localhost"`
+		Port  string `test_kv:"db/port" default:"3306"`
+		Other string `test_kv:"db/host" env:"other"`
+	}
+
+	os.Setenv("OTHER", "other")
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.AddSource(mapSource{"Host": "source"})
+
+	err := configurator.Load(&c)
+	require.NoError(t, err)
+	assert.Equal(t, config{Host: "db.example.com", Port: "3306", Other: "other"}, c)
+
+	os.Clearenv()
+}
+
+func TestRegisterTag_Duplicate(t *testing.T) {
+	assert.Panics(t, func() {
+		nest.RegisterTag("test_description", usageTagHandler{})
+	})
+}
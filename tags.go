@@ -2,16 +2,69 @@ package nest
 
 // Tag constants
 const (
-	TagIgnored    = "ignored"
-	TagDefault    = "default"
-	TagRequired   = "required"
-	TagSplitWords = "split_words"
+	TagIgnored      = "ignored"
+	TagIgnoreFields = "ignore_fields"
+	TagEnum         = "enum"
+	TagSplitWords   = "split_words"
 
+	// TagDefault sets the default value of a field.
+	// Mode specific defaults can be set by suffixing the tag with the mode set by SetMode (eg. default.production:"100").
+	TagDefault = "default"
+
+	// TagRequired makes a field required (required:"true")
+	// or required only in some modes set by SetMode (eg. required:"production,staging").
+	TagRequired = "required"
+
+	// TagPrefix sets the key prefix of the fields of a child struct.
+	// An empty prefix (prefix:"") merges the fields into the parent namespace,
+	// without the tag the name of the struct field is used as prefix.
 	TagPrefix = "prefix"
+	TagDecode = "decode"
+
+	// TagAlias lists former keys of a field (eg. alias:"old.key.path"), which are still accepted
+	// from sources, flags and environment variables (if the field has them).
+	TagAlias = "alias"
+
+	// TagAliasDeprecatedAfter sets the date (eg. 2025-12-31) after which using the aliases of a field is an error.
+	TagAliasDeprecatedAfter = "alias_deprecated_after"
 
 	TagEnvironment = "env"
 
 	TagFlag = "flag"
 
-	TagUsage = "usage"
+	TagUsage   = "usage"
+	TagSection = "section"
+	TagExample = "example"
+	TagWeight  = "weight"
+
+	// Descriptive tags for documentation, they do not affect decoding
+	TagUnit   = "unit"
+	TagFormat = "format"
+
+	TagSecret = "secret"
+	TagTTL    = "ttl"
+
+	TagTemplate = "template"
+
+	// TagTrim strips leading and trailing whitespace (including trailing newlines) from the value.
+	// It can be used for disabling trimming enabled by SetTrimSpace as well (trim:"false").
+	TagTrim = "trim"
+
+	// TagUnquote strips a pair of surrounding quotes from the value.
+	// Escape sequences (eg. \n in PEM blocks stored on a single line) are interpreted in double quoted values.
+	TagUnquote = "unquote"
+
+	// TagSeparator sets the separator of the elements of slice values (defaults to ",").
+	TagSeparator = "separator"
+
+	// TagSetter names the method the loaded value of a field is passed to (eg. setter:"SetPort"),
+	// so that unexported fields can be configured as well.
+	TagSetter = "setter"
+
+	TagSourceOf = "source_of"
+	TagMetric   = "metric"
+
+	// Tags of kelseyhightower/envconfig (honored in compatibility mode)
+	TagEnvconfig = "envconfig"
+	TagDesc      = "desc"
 )
@@ -0,0 +1,23 @@
+package nest
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// expandTemplate executes a value as a template with the configuration struct as data.
+func expandTemplate(text string, data interface{}) (string, error) {
+	tpl, err := template.New("").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+
+	err = tpl.Execute(&buf, data)
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
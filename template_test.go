@@ -0,0 +1,54 @@
+package nest_test
+
+import (
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigurator_Load_Template(t *testing.T) {
+	type subconfig struct {
+		Host string `default:"localhost"`
+		Port int    `default:"8080"`
+	}
+
+	type config struct {
+		Addr    string `default:"{{ .Sconfig.Host }}:{{ .Sconfig.Port }}" template:"true"`
+		URL     string `default:"http://{{ .Addr }}/" template:"true"`
+		Raw     string `default:"{{ .Sconfig.Host }}"`
+		Sconfig subconfig
+	}
+
+	expected := config{
+		Addr: "localhost:8080",
+		URL:  "http://localhost:8080/",
+		Raw:  "{{ .Sconfig.Host }}",
+		Sconfig: subconfig{
+			Host: "localhost",
+			Port: 8080,
+		},
+	}
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+
+	err := configurator.Load(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestConfigurator_Load_TemplateError(t *testing.T) {
+	type config struct {
+		Addr string `default:"{{ .Missing }}" template:"true"`
+	}
+
+	actual := config{}
+
+	configurator := nest.NewConfigurator()
+
+	err := configurator.Load(&actual)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to expand template for field Addr")
+}
@@ -0,0 +1,40 @@
+package nest
+
+import "fmt"
+
+// Message IDs passed to the Translator.
+//
+// Each ID is also the default (English) format string of the message.
+const (
+	MsgUsage             = "Usage of %s:"
+	MsgVersion           = "%s version %s"
+	MsgConfigValid       = "Configuration is valid"
+	MsgFlagsTitle        = "FLAGS"
+	MsgEnvTitle          = "ENVIRONMENT VARIABLES"
+	MsgDefault           = "(default %s)"
+	MsgRequired          = "(required)"
+	MsgExample           = "(example: %s)"
+	MsgUnit              = "(unit: %s)"
+	MsgFormat            = "(format: %s)"
+	MsgRequiredMissing   = "required field %s missing value"
+	MsgDecryptFailed     = "failed to decrypt %s: %v"
+	MsgTemplateFailed    = "failed to expand template for field %s: %v"
+	MsgInvalidTTL        = "invalid ttl for field %s: %v"
+	MsgInvalidChoice     = "invalid value %q for field %s, must be one of: %s"
+	MsgAliasExpired      = "%s %s is not supported after %s, use %s instead"
+	MsgConflictingValues = "conflicting values for field %s: flag %s and environment variable %s differ"
+)
+
+// Translator returns the localized version of a message.
+//
+// The message ID is one of the Msg constants, args are the values referenced by it's format verbs.
+type Translator func(msgID string, args ...interface{}) string
+
+// defaultTranslator formats the message using the message ID as format string.
+func defaultTranslator(msgID string, args ...interface{}) string {
+	if len(args) == 0 {
+		return msgID
+	}
+
+	return fmt.Sprintf(msgID, args...)
+}
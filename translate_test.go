@@ -0,0 +1,64 @@
+package nest_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var germanCatalog = map[string]string{
+	nest.MsgUsage:           "Verwendung von %s:",
+	nest.MsgFlagsTitle:      "OPTIONEN",
+	nest.MsgDefault:         "(Standard: %s)",
+	nest.MsgRequired:        "(erforderlich)",
+	nest.MsgRequiredMissing: "Pflichtfeld %s hat keinen Wert",
+}
+
+func germanTranslator(msgID string, args ...interface{}) string {
+	if msg, ok := germanCatalog[msgID]; ok {
+		msgID = msg
+	}
+
+	return fmt.Sprintf(msgID, args...)
+}
+
+func TestConfigurator_SetTranslator_Usage(t *testing.T) {
+	type config struct {
+		Value    string `flag:"value" default:"value" usage:"My value"`
+		Required string `flag:"required" required:"true" usage:"My required value"`
+	}
+
+	c := config{}
+
+	var buf bytes.Buffer
+
+	configurator := nest.NewConfigurator()
+	configurator.SetName("app")
+	configurator.SetTranslator(germanTranslator)
+
+	err := configurator.Usage(&c, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, "Verwendung von app:\n\n\nOPTIONEN:\n\n      --value string      My value (Standard: \"value\")\n      --required string   My required value (erforderlich)\n", buf.String())
+}
+
+func TestConfigurator_SetTranslator_Error(t *testing.T) {
+	os.Clearenv()
+
+	type config struct {
+		Value string `required:"true"`
+	}
+
+	c := config{}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+	configurator.SetTranslator(germanTranslator)
+
+	err := configurator.Load(&c)
+	assert.EqualError(t, err, "Pflichtfeld Value hat keinen Wert")
+}
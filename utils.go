@@ -1,8 +1,12 @@
 package nest
 
 import (
+	"fmt"
+	"io"
+	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
@@ -22,6 +26,113 @@ func isZeroValueOfType(x interface{}) bool {
 	return reflect.DeepEqual(x, reflect.Zero(reflect.TypeOf(x)).Interface())
 }
 
+// formatValue formats a value as string (like fmt.Sprintf("%v") does) avoiding allocations for common types.
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+
+	case bool:
+		return strconv.FormatBool(v)
+
+	case int:
+		return strconv.Itoa(v)
+
+	case int64:
+		return strconv.FormatInt(v, 10)
+
+	case uint64:
+		return strconv.FormatUint(v, 10)
+
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+
+	case []byte:
+		return string(v)
+	}
+
+	if v := reflect.ValueOf(value); isJoinable(v) {
+		return joinValue(v, defaultSeparator)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// defaultSeparator separates the elements of slice values.
+const defaultSeparator = ","
+
+// formatSeparatedValue formats a value as string joining the elements of slices with the separator.
+func formatSeparatedValue(value interface{}, separator string) string {
+	if v := reflect.ValueOf(value); isJoinable(v) {
+		return joinValue(v, separator)
+	}
+
+	return formatValue(value)
+}
+
+// isJoinable checks whether a value is a slice or map formatted by joining it's elements
+// (byte slices and values formatting themselves are not).
+func isJoinable(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return false
+		}
+
+	case reflect.Map:
+
+	default:
+		return false
+	}
+
+	_, isStringer := v.Interface().(fmt.Stringer)
+
+	return !isStringer
+}
+
+// joinValue formats the elements of a slice (or the key=value pairs of a map ordered by key)
+// and joins them with the separator.
+func joinValue(v reflect.Value, separator string) string {
+	if v.Kind() == reflect.Map {
+		elements := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			elements = append(elements, formatValue(key.Interface())+"="+formatValue(v.MapIndex(key).Interface()))
+		}
+
+		// Entries are ordered by key, so that the output is stable
+		sort.Slice(elements, func(i, j int) bool {
+			return mapEntryKey(elements[i]) < mapEntryKey(elements[j])
+		})
+
+		return strings.Join(elements, separator)
+	}
+
+	elements := make([]string, v.Len())
+	for i := range elements {
+		elements[i] = formatValue(v.Index(i).Interface())
+	}
+
+	return strings.Join(elements, separator)
+}
+
+// mapEntryKey returns the key of a formatted key=value map entry.
+func mapEntryKey(entry string) string {
+	return entry[:strings.Index(entry, "=")]
+}
+
+// splitSeparatedValue splits a value into elements by the separator and trims surrounding whitespace from them.
+func splitSeparatedValue(value string, separator string) []string {
+	elements := strings.Split(value, separator)
+	for i, element := range elements {
+		elements[i] = strings.TrimSpace(element)
+	}
+
+	return elements
+}
+
 // isTrue checks whether a string contains a value which can be parsed into "true" boolean value.
 func isTrue(s string) bool {
 	b, _ := strconv.ParseBool(s)
@@ -29,12 +140,52 @@ func isTrue(s string) bool {
 	return b
 }
 
+// isSameValue checks whether two raw values set the same value on a field.
+// Boolean values are compared after parsing (eg. 1 and true are the same).
+func isSameValue(def fieldDefinition, a string, b string) bool {
+	if def.field.Kind() == reflect.Bool {
+		x, errx := parseBool(a)
+		y, erry := parseBool(b)
+
+		if errx == nil && erry == nil {
+			return x == y
+		}
+	}
+
+	return a == b
+}
+
+// unquoteValue strips a pair of matching single or double quotes surrounding a value.
+// Escape sequences are interpreted in double quoted values, single quoted values are kept as is (like in shells).
+// Values without surrounding quotes are returned unchanged.
+func unquoteValue(value string) string {
+	if len(value) < 2 || value[0] != value[len(value)-1] {
+		return value
+	}
+
+	switch value[0] {
+	case '"':
+		// Literal newlines (eg. of multi-line PEM blocks) are not accepted by strconv.Unquote
+		escaped := strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(value)
+
+		if unquoted, err := strconv.Unquote(escaped); err == nil {
+			return unquoted
+		}
+
+		return value[1 : len(value)-1]
+
+	case '\'':
+		return value[1 : len(value)-1]
+	}
+
+	return value
+}
+
 // lowerFirst converts the first character of a string to lower case.
 func lowerFirst(s string) string {
-	a := []rune(s)
-	a[0] = unicode.ToLower(a[0])
+	r, size := utf8.DecodeRuneInString(s)
 
-	return string(a)
+	return string(unicode.ToLower(r)) + s[size:]
 }
 
 // splitWords splits a camel cased string and converts it to snake or spinal case (according to the glue string).
@@ -58,3 +209,36 @@ func isExported(name string) bool {
 
 	return unicode.IsUpper(r)
 }
+
+// envPrefixFromName derives an environment variable prefix from an application name.
+//
+// Characters other than letters and digits are replaced with underscores (eg. "my service" becomes "MY_SERVICE").
+func envPrefixFromName(name string) string {
+	name = filepath.Base(name)
+	if name == "." || name == string(filepath.Separator) {
+		return ""
+	}
+
+	prefix := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+
+		return '_'
+	}, name)
+
+	return strings.Trim(prefix, "_")
+}
+
+// trackingWriter records whether anything has been written to the underlying writer.
+type trackingWriter struct {
+	w       io.Writer
+	written bool
+}
+
+// Write implements the io.Writer interface.
+func (w *trackingWriter) Write(p []byte) (int, error) {
+	w.written = true
+
+	return w.w.Write(p)
+}
@@ -1,6 +1,7 @@
 package nest
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -63,6 +64,27 @@ func TestLowerFirst(t *testing.T) {
 	}
 }
 
+func TestUnquoteValue(t *testing.T) {
+	tests := map[string]string{
+		`value`:             "value",
+		`"value"`:           "value",
+		`'value'`:           "value",
+		`"line1\nline2"`:    "line1\nline2",
+		`'line1\nline2'`:    `line1\nline2`,
+		"\"line1\nline2\"":  "line1\nline2",
+		`"unterminated`:     `"unterminated`,
+		`"mismatched'`:      `"mismatched'`,
+		`"`:                 `"`,
+		`"invalid \escape"`: `invalid \escape`,
+	}
+
+	for input, expected := range tests {
+		t.Run("", func(t *testing.T) {
+			assert.Equal(t, expected, unquoteValue(input))
+		})
+	}
+}
+
 func TestSplitWords_Snake(t *testing.T) {
 	tests := map[string]string{
 		"CamelCase": "camel_case",
@@ -107,3 +129,46 @@ func TestIsExported(t *testing.T) {
 		})
 	}
 }
+
+func TestEnvPrefixFromName(t *testing.T) {
+	tests := map[string]string{
+		"my service":         "MY_SERVICE",
+		"my-service":         "MY_SERVICE",
+		"/usr/bin/myservice": "MYSERVICE",
+		"-service-":          "SERVICE",
+		"":                   "",
+	}
+
+	for input, expected := range tests {
+		t.Run(input, func(t *testing.T) {
+			assert.Equal(t, expected, envPrefixFromName(input))
+		})
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := map[string]interface{}{
+		"string":   "string",
+		"bool":     true,
+		"int":      int(-1),
+		"int8":     int8(-1),
+		"int64":    int64(-1),
+		"uint":     uint(1),
+		"uint64":   uint64(1),
+		"float32":  float32(1.1),
+		"float64":  float64(1e21),
+		"duration": 10 * time.Second,
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, fmt.Sprintf("%v", test), formatValue(test))
+		})
+	}
+}
+
+func TestFormatValue_Map(t *testing.T) {
+	assert.Equal(t, "a=1,b=2,c=3", formatValue(map[string]int{"c": 3, "a": 1, "b": 2}))
+	assert.Equal(t, "a=1;b=2", formatSeparatedValue(map[string]string{"b": "2", "a": "1"}, ";"))
+	assert.Equal(t, "", formatValue(map[string]string{}))
+}
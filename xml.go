@@ -0,0 +1,89 @@
+package nest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// parseXML parses an XML document.
+//
+// The root element is omitted from the keys: child elements and attributes become keys of the (nested) map,
+// repeated elements are collected into a slice and the text content of leaf elements becomes the value.
+func parseXML(data []byte) (map[string]interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, errors.New("missing root element")
+		} else if err != nil {
+			return nil, err
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			value, err := parseXMLElement(decoder, start)
+			if err != nil {
+				return nil, err
+			}
+
+			if doc, ok := value.(map[string]interface{}); ok {
+				return doc, nil
+			}
+
+			return map[string]interface{}{}, nil
+		}
+	}
+}
+
+// parseXMLElement reads the content of an element (after it's start element).
+// It returns a map for elements with children or attributes, and the text content otherwise.
+func parseXMLElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	children := make(map[string]interface{})
+
+	for _, attr := range start.Attr {
+		children[attr.Name.Local] = attr.Value
+	}
+
+	var text bytes.Buffer
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			value, err := parseXMLElement(decoder, t)
+			if err != nil {
+				return nil, err
+			}
+
+			name := t.Name.Local
+
+			switch existing := children[name].(type) {
+			case nil:
+				children[name] = value
+
+			case []interface{}:
+				children[name] = append(existing, value)
+
+			default:
+				children[name] = []interface{}{existing, value}
+			}
+
+		case xml.CharData:
+			text.Write(t)
+
+		case xml.EndElement:
+			if len(children) == 0 {
+				return strings.TrimSpace(text.String()), nil
+			}
+
+			return children, nil
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package zapredact hides the values of secret configuration fields in zap log output.
+//
+// The core wrapper redacts messages and fields using the Redactor of a load report:
+//
+//	report, err := configurator.LoadWithReport(&config)
+//	// ...
+//
+//	logger := zap.New(zapredact.NewCore(core, report.Redactor()))
+package zapredact
+
+import (
+	"fmt"
+
+	"github.com/goph/nest"
+	"go.uber.org/zap/zapcore"
+)
+
+// core is a zapcore.Core redacting entries before passing them to the wrapped core.
+type core struct {
+	zapcore.Core
+
+	redactor *nest.Redactor
+}
+
+// NewCore returns a core hiding secret values in the messages and fields written to the wrapped core.
+//
+// Fields named after secret fields are hidden entirely, secret values are replaced in every other string,
+// error and fmt.Stringer field.
+func NewCore(c zapcore.Core, redactor *nest.Redactor) zapcore.Core {
+	return &core{
+		Core:     c,
+		redactor: redactor,
+	}
+}
+
+// With implements the zapcore.Core interface.
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	return &core{
+		Core:     c.Core.With(c.redactFields(fields)),
+		redactor: c.redactor,
+	}
+}
+
+// Check implements the zapcore.Core interface.
+func (c *core) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+
+	return checked
+}
+
+// Write implements the zapcore.Core interface.
+func (c *core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = c.redactor.Redact(entry.Message)
+
+	return c.Core.Write(entry, c.redactFields(fields))
+}
+
+// redactFields returns a copy of the fields with the secret values hidden.
+func (c *core) redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+
+	for i, field := range fields {
+		redacted[i] = c.redactField(field)
+	}
+
+	return redacted
+}
+
+// redactField hides the secret values of a field.
+func (c *core) redactField(field zapcore.Field) zapcore.Field {
+	var value string
+
+	switch field.Type {
+	case zapcore.StringType:
+		value = field.String
+
+	case zapcore.ByteStringType:
+		value = string(field.Interface.([]byte))
+
+	case zapcore.ErrorType:
+		value = field.Interface.(error).Error()
+
+	case zapcore.StringerType:
+		value = field.Interface.(fmt.Stringer).String()
+
+	default:
+		if !c.redactor.IsSecretKey(field.Key) {
+			return field
+		}
+	}
+
+	return zapcore.Field{
+		Key:    field.Key,
+		Type:   zapcore.StringType,
+		String: c.redactor.RedactField(field.Key, value),
+	}
+}
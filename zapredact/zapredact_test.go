@@ -0,0 +1,48 @@
+package zapredact_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/goph/nest"
+	"github.com/goph/nest/zapredact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNewCore(t *testing.T) {
+	type config struct {
+		Host     string `default:"example.com"`
+		Password string `default:"hunter2" secret:"true"`
+	}
+
+	configurator := nest.NewConfigurator()
+	configurator.SetArgs([]string{"app"})
+
+	report, err := configurator.LoadWithReport(&config{})
+	require.NoError(t, err)
+
+	observed, logs := observer.New(zap.DebugLevel)
+	logger := zap.New(zapredact.NewCore(observed, report.Redactor()))
+
+	logger.With(zap.String("password", "anything")).Info(
+		"connecting to example.com with hunter2",
+		zap.String("dsn", "user:hunter2@example.com"),
+		zap.Error(errors.New("login failed with hunter2")),
+		zap.Int("port", 5432),
+	)
+
+	require.Equal(t, 1, logs.Len())
+
+	entry := logs.All()[0]
+
+	assert.Equal(t, "connecting to example.com with <hidden>", entry.Message)
+	assert.Equal(t, map[string]interface{}{
+		"password": "<hidden>",
+		"dsn":      "user:<hidden>@example.com",
+		"error":    "login failed with <hidden>",
+		"port":     int64(5432),
+	}, entry.ContextMap())
+}